@@ -3,8 +3,142 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
 )
 
+const maxCharactersPerAccount = 5
+
+var (
+	characterNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,12}$`)
+
+	// Skins are the character skins open to every account.
+	Skins = []string{"men1", "men2", "men3", "women1", "women2", "women3"}
+)
+
+type CreateCharacterBody struct {
+	Name string `json:"name"`
+	Skin string `json:"skin"`
+}
+
+type DeleteCharacterBody struct {
+	Name string `json:"name"`
+}
+
+type AccountDetailsResponse struct {
+	Data  AccountDetails `json:"data"`
+	Error ErrorMessage   `json:"error"`
+}
+
+type AccountDetails struct {
+	Username           string   `json:"username"`
+	Email              string   `json:"email"`
+	Subscribed         bool     `json:"subscribed"`
+	Status             string   `json:"status"`
+	Badges             []string `json:"badges"`
+	Gems               int      `json:"gems"`
+	AchievementsPoints int      `json:"achievements_points"`
+	Banned             bool     `json:"banned"`
+	BanReason          string   `json:"ban_reason"`
+}
+
+type AchievementsResponse struct {
+	Data  []Achievement `json:"data"`
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+	Size  int           `json:"size"`
+	Pages int           `json:"pages"`
+	Error ErrorMessage  `json:"error"`
+}
+
+type Achievement struct {
+	Name        string             `json:"name"`
+	Code        string             `json:"code"`
+	Description string             `json:"description"`
+	Points      int                `json:"points"`
+	Type        string             `json:"type"`
+	Target      string             `json:"target"`
+	Total       int                `json:"total"`
+	Rewards     AchievementRewards `json:"rewards"`
+	Current     int                `json:"current"`
+	CompletedAt string             `json:"completed_at"`
+}
+
+type AchievementRewards struct {
+	Gold int `json:"gold"`
+}
+
+// Progress returns the completed fraction of the achievement.
+func (a Achievement) Progress() float64 {
+	if a.Total <= 0 {
+		return 0
+	}
+	return float64(a.Current) / float64(a.Total)
+}
+
+// Activity describes what would progress the achievement.
+func (a Achievement) Activity() string {
+	switch a.Type {
+	case "combat_kill":
+		return fmt.Sprintf("fight %s", a.Target)
+	case "combat_drop":
+		return fmt.Sprintf("fight for %s", a.Target)
+	case "combat_level":
+		return "level combat"
+	case "gathering":
+		return fmt.Sprintf("gather %s", a.Target)
+	case "crafting":
+		return fmt.Sprintf("craft %s", a.Target)
+	case "recycling":
+		return fmt.Sprintf("recycle %s", a.Target)
+	case "task":
+		return "complete tasks"
+	case "use":
+		return fmt.Sprintf("use %s", a.Target)
+	}
+	return a.Description
+}
+
+type CharacterLeaderboardResponse struct {
+	Data  []CharacterLeaderboardEntry `json:"data"`
+	Total int                         `json:"total"`
+	Page  int                         `json:"page"`
+	Size  int                         `json:"size"`
+	Pages int                         `json:"pages"`
+	Error ErrorMessage                `json:"error"`
+}
+
+type CharacterLeaderboardEntry struct {
+	Position           int    `json:"position"`
+	Name               string `json:"name"`
+	Account            string `json:"account"`
+	Skin               string `json:"skin"`
+	AchievementsPoints int    `json:"achievements_points"`
+	Level              int    `json:"level"`
+	TotalXp            int    `json:"total_xp"`
+	Gold               int    `json:"gold"`
+}
+
+type AccountLeaderboardResponse struct {
+	Data  []AccountLeaderboardEntry `json:"data"`
+	Total int                       `json:"total"`
+	Page  int                       `json:"page"`
+	Size  int                       `json:"size"`
+	Pages int                       `json:"pages"`
+	Error ErrorMessage              `json:"error"`
+}
+
+type AccountLeaderboardEntry struct {
+	Position           int    `json:"position"`
+	Account            string `json:"account"`
+	AchievementsPoints int    `json:"achievements_points"`
+	Gold               int    `json:"gold"`
+}
+
 func (c *ArtifactsClient) GetCharacter(name string) (*CharacterResponse, error) {
 	path := fmt.Sprintf("/characters/%s", name)
 	respBytes, err := c.Do("GET", path, nil, nil)
@@ -38,3 +172,304 @@ func (c *ArtifactsClient) GetCharacters() ([]*Character, error) {
 	}
 	return charResp.Characters, nil
 }
+
+func (c *ArtifactsClient) GetAccountDetails() (*AccountDetails, error) {
+	respBytes, err := c.Do(http.MethodGet, "/my/details", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+
+	detailsResp := AccountDetailsResponse{}
+	if err := json.Unmarshal(respBytes, &detailsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if detailsResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", detailsResp.Error.Code, detailsResp.Error.Message)
+	}
+	return &detailsResp.Data, nil
+}
+
+func (c *ArtifactsClient) GetAchievements(account string, pageNumber int) (*AchievementsResponse, error) {
+	path := fmt.Sprintf("/accounts/%s/achievements", account)
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, path, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+
+	achievementsResp := AchievementsResponse{}
+	if err := json.Unmarshal(respBytes, &achievementsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if achievementsResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", achievementsResp.Error.Code, achievementsResp.Error.Message)
+	}
+	return &achievementsResp, nil
+}
+
+// GetCharacterLeaderboard returns a page of the character leaderboard ordered by
+// sortBy, a skill or "combat", "achievements_points" or "gold".
+func (c *ArtifactsClient) GetCharacterLeaderboard(sortBy string, pageNumber int) (*CharacterLeaderboardResponse, error) {
+	p := map[string]string{
+		"sort": sortBy,
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/leaderboard/characters", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+
+	leaderboardResp := CharacterLeaderboardResponse{}
+	if err := json.Unmarshal(respBytes, &leaderboardResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if leaderboardResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", leaderboardResp.Error.Code, leaderboardResp.Error.Message)
+	}
+	return &leaderboardResp, nil
+}
+
+// GetAccountLeaderboard returns a page of the account leaderboard ordered by sortBy,
+// "achievements_points" or "gold".
+func (c *ArtifactsClient) GetAccountLeaderboard(sortBy string, pageNumber int) (*AccountLeaderboardResponse, error) {
+	p := map[string]string{
+		"sort": sortBy,
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/leaderboard/accounts", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+
+	leaderboardResp := AccountLeaderboardResponse{}
+	if err := json.Unmarshal(respBytes, &leaderboardResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if leaderboardResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", leaderboardResp.Error.Code, leaderboardResp.Error.Message)
+	}
+	return &leaderboardResp, nil
+}
+
+func (c *Svc) GetAccountDetails() (*AccountDetails, error) {
+	details, err := c.Client.GetAccountDetails()
+	if err != nil {
+		return nil, fmt.Errorf("getting account details: %w", err)
+	}
+	return details, nil
+}
+
+// GetAchievements lists every achievement of account with its progress.
+func (c *Svc) GetAchievements(account string) ([]Achievement, error) {
+	achievements := []Achievement{}
+	for page := 1; ; page++ {
+		resp, err := c.Client.GetAchievements(account, page)
+		if err != nil {
+			return nil, fmt.Errorf("getting achievements page %d: %w", page, err)
+		}
+		achievements = append(achievements, resp.Data...)
+		if len(resp.Data) == 0 || page >= resp.Pages {
+			return achievements, nil
+		}
+	}
+}
+
+// ClosestAchievements returns up to n of our account's unfinished achievements,
+// closest to completion first. Achievement.Activity tells what progresses each one.
+func (c *Svc) ClosestAchievements(n int) ([]Achievement, error) {
+	details, err := c.GetAccountDetails()
+	if err != nil {
+		return nil, fmt.Errorf("getting account: %w", err)
+	}
+	achievements, err := c.GetAchievements(details.Username)
+	if err != nil {
+		return nil, fmt.Errorf("getting achievements: %w", err)
+	}
+
+	open := []Achievement{}
+	for _, achievement := range achievements {
+		if achievement.CompletedAt == "" && achievement.Current < achievement.Total {
+			open = append(open, achievement)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if pi, pj := open[i].Progress(), open[j].Progress(); pi != pj {
+			return pi > pj
+		}
+		return open[i].Total-open[i].Current < open[j].Total-open[j].Current
+	})
+	if n > 0 && len(open) > n {
+		open = open[:n]
+	}
+	return open, nil
+}
+
+func (c *ArtifactsClient) CreateCharacter(name, skin string) (*Character, error) {
+	bodyBytes, err := json.Marshal(CreateCharacterBody{Name: name, Skin: skin})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	return c.characterLifecycle("/characters/create", bodyBytes)
+}
+
+func (c *ArtifactsClient) DeleteCharacter(name string) (*Character, error) {
+	bodyBytes, err := json.Marshal(DeleteCharacterBody{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	return c.characterLifecycle("/characters/delete", bodyBytes)
+}
+
+func (c *ArtifactsClient) characterLifecycle(path string, bodyBytes []byte) (*Character, error) {
+	respBytes, err := c.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+
+	charResp := CharacterResponse{}
+	if err := json.Unmarshal(respBytes, &charResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if charResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", charResp.Error.Code, charResp.Error.Message)
+	}
+	return &charResp.Character, nil
+}
+
+// validateCharacterName checks name against the server's naming rules.
+func validateCharacterName(name string) error {
+	if !characterNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid character name %q: use 3 to 12 letters, digits, _ or -", name)
+	}
+	return nil
+}
+
+// CreateCharacter creates a character on the account and starts tracking it.
+func (c *Svc) CreateCharacter(name, skin string) (*Character, error) {
+	if err := validateCharacterName(name); err != nil {
+		return nil, err
+	}
+	if skin == "" {
+		return nil, fmt.Errorf("a skin is required, e.g. one of %v", Skins)
+	}
+
+	if c.GetCharacterByName(name) != nil {
+		return nil, fmt.Errorf("character %s already exists", name)
+	}
+	// count on the server so characters made elsewhere are included
+	existing, err := c.Client.GetCharacters()
+	if err != nil {
+		return nil, fmt.Errorf("getting characters: %w", err)
+	}
+	if len(existing) >= maxCharactersPerAccount {
+		return nil, fmt.Errorf("account already has %d characters", len(existing))
+	}
+
+	fmt.Printf("Creating character %s\n", name)
+	character, err := c.Client.CreateCharacter(name, skin)
+	if err != nil {
+		return nil, fmt.Errorf("creating character: %w", err)
+	}
+	c.setCharacter(character.Name, character)
+	return character, nil
+}
+
+// DeleteCharacter deletes a character from the account, stops tracking it and drops
+// its policies, loadouts and task ledger. Characters running a loop can't be deleted.
+func (c *Svc) DeleteCharacter(name string) error {
+	c.charactersMu.Lock()
+	character, exists := c.Characters[name]
+	if !exists {
+		c.charactersMu.Unlock()
+		return fmt.Errorf("unknown character %s", name)
+	}
+	if c.loops[name] > 0 {
+		c.charactersMu.Unlock()
+		return fmt.Errorf("character %s is busy, stop its loops first", name)
+	}
+	// untrack first so no loop can start while the server deletes it
+	delete(c.Characters, name)
+	c.charactersMu.Unlock()
+
+	fmt.Printf("Deleting character %s\n", name)
+	if _, err := c.Client.DeleteCharacter(name); err != nil {
+		c.setCharacter(name, character)
+		return fmt.Errorf("deleting character: %w", err)
+	}
+	if err := c.forgetCharacter(name); err != nil {
+		return fmt.Errorf("clearing %s state: %w", name, err)
+	}
+	return nil
+}
+
+// startLoop marks the character as running a long task until the returned function
+// is called, so it isn't deleted from under the loop.
+func (c *Svc) startLoop(characterName string) (func(), error) {
+	c.charactersMu.Lock()
+	defer c.charactersMu.Unlock()
+	if _, exists := c.Characters[characterName]; !exists {
+		return nil, fmt.Errorf("unknown character %s", characterName)
+	}
+	c.loops[characterName]++
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			c.charactersMu.Lock()
+			defer c.charactersMu.Unlock()
+			if c.loops[characterName]--; c.loops[characterName] <= 0 {
+				delete(c.loops, characterName)
+			}
+		})
+	}, nil
+}
+
+// forgetCharacter drops everything kept per character and persists the stores
+// holding it.
+func (c *Svc) forgetCharacter(characterName string) error {
+	c.Loadouts.mu.Lock()
+	delete(c.Loadouts.ByCharacter, characterName)
+	delete(c.Loadouts.active, characterName)
+	err := writeJSONFile(filepath.Join(c.DataDir, loadoutsFile), c.Loadouts.ByCharacter)
+	c.Loadouts.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("saving loadouts: %w", err)
+	}
+
+	c.TaskEconomy.mu.Lock()
+	delete(c.TaskEconomy.Policies, characterName)
+	delete(c.TaskEconomy.Ledgers, characterName)
+	err = writeJSONFile(filepath.Join(c.DataDir, taskLedgerFile), c.TaskEconomy.Ledgers)
+	c.TaskEconomy.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("saving task ledger: %w", err)
+	}
+
+	c.AutoEquip.mu.Lock()
+	delete(c.AutoEquip.Policies, characterName)
+	delete(c.AutoEquip.pending, characterName)
+	c.AutoEquip.mu.Unlock()
+
+	c.Utilities.mu.Lock()
+	delete(c.Utilities.Policies, characterName)
+	delete(c.Utilities.used, characterName)
+	c.Utilities.mu.Unlock()
+
+	c.Healing.mu.Lock()
+	delete(c.Healing.Policies, characterName)
+	c.Healing.mu.Unlock()
+
+	c.Reservations.mu.Lock()
+	delete(c.Reservations.ByCharacter, characterName)
+	c.Reservations.mu.Unlock()
+
+	c.Inventories.mu.Lock()
+	delete(c.Inventories.Policies, characterName)
+	c.Inventories.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AutoEquipPolicy controls whether newly crafted or dropped gear is equipped when
+// it beats what a character is wearing.
+type AutoEquipPolicy struct {
+	Enabled bool
+	// ShareWithAccount banks upgrades the owner can't use for other characters to pick up.
+	ShareWithAccount bool
+	// RecycleReplaced recycles the replaced piece instead of depositing it.
+	RecycleReplaced bool
+}
+
+type AutoEquip struct {
+	mu       sync.Mutex
+	Policies map[string]AutoEquipPolicy
+	pending  map[string][]string
+}
+
+func NewAutoEquip() AutoEquip {
+	return AutoEquip{
+		mu:       sync.Mutex{},
+		Policies: make(map[string]AutoEquipPolicy),
+		pending:  make(map[string][]string),
+	}
+}
+
+func (c *Svc) SetAutoEquipPolicy(characterName string, policy AutoEquipPolicy) {
+	c.AutoEquip.mu.Lock()
+	defer c.AutoEquip.mu.Unlock()
+	c.AutoEquip.Policies[characterName] = policy
+}
+
+func (c *Svc) autoEquipPolicy(characterName string) AutoEquipPolicy {
+	c.AutoEquip.mu.Lock()
+	defer c.AutoEquip.mu.Unlock()
+	return c.AutoEquip.Policies[characterName]
+}
+
+// upgradeSlot returns the slot item should go in if it is an upgrade for the character.
+func (c *Svc) upgradeSlot(character *Character, item CraftableItem) (Slot, bool) {
+	if !item.IsEquippable() || item.Type == "utility" || item.Level > character.Level {
+		return "", false
+	}
+	if item.Type == "artifact" && character.IsEquipped(item.Code) {
+		return "", false
+	}
+	if slot, free := character.FreeSlotFor(item); free {
+		return slot, true
+	}
+
+	newScore := c.scoreItem(character, item, GearTarget{})
+	var worstSlot Slot
+	var worst CraftableItem
+	worstScore := 0.0
+	for _, slot := range SlotsForItemType(item.Type) {
+		current := c.GetItem(character.EquippedItem(slot).Code)
+		score := c.scoreItem(character, current, GearTarget{})
+		if worstSlot == "" || score < worstScore {
+			worstSlot, worst, worstScore = slot, current, score
+		}
+	}
+
+	if newScore > worstScore || (newScore == worstScore && item.Level > worst.Level) {
+		return worstSlot, true
+	}
+	return "", false
+}
+
+// offerUpgrade equips a new item on its owner when it is an upgrade, or banks it for
+// another character on the account when the policy allows sharing.
+func (c *Svc) offerUpgrade(characterName, code string) error {
+	policy := c.autoEquipPolicy(characterName)
+	if !policy.Enabled {
+		return nil
+	}
+
+	c.Loadouts.mu.Lock()
+	activeLoadout := c.Loadouts.active[characterName]
+	c.Loadouts.mu.Unlock()
+	if activeLoadout != "" {
+		// gear is managed by the activity loadout
+		return nil
+	}
+
+	item := c.GetItem(code)
+	if slot, ok := c.upgradeSlot(c.GetCharacterByName(characterName), item); ok {
+		return c.equipUpgrade(characterName, item, slot, policy)
+	}
+	if !policy.ShareWithAccount {
+		return nil
+	}
+
+	for name, character := range c.GetAllCharacters() {
+		if name == characterName {
+			continue
+		}
+		if _, ok := c.upgradeSlot(character, item); !ok {
+			continue
+		}
+
+		fmt.Printf("%s banking %s as an upgrade for %s\n", characterName, code, name)
+		if err := c.DepositBank(characterName, InventorySlot{Code: code, Quantity: 1}); err != nil {
+			return fmt.Errorf("depositing %s for %s: %w", code, name, err)
+		}
+		c.GetCharacterByName(characterName).WaitForCooldown()
+
+		c.AutoEquip.mu.Lock()
+		c.AutoEquip.pending[name] = append(c.AutoEquip.pending[name], code)
+		c.AutoEquip.mu.Unlock()
+		return nil
+	}
+	return nil
+}
+
+func (c *Svc) equipUpgrade(characterName string, item CraftableItem, slot Slot, policy AutoEquipPolicy) error {
+	fmt.Printf("%s upgrading %s with %s\n", characterName, slot, item.Code)
+	if err := c.fetchItem(characterName, item.Code, 1); err != nil {
+		return fmt.Errorf("fetching %s: %w", item.Code, err)
+	}
+
+	replaced := c.GetCharacterByName(characterName).EquippedItem(slot)
+	if replaced.Code != "" {
+		if err := c.Unequip(characterName, slot, replaced.Quantity); err != nil {
+			return fmt.Errorf("unequipping %s: %w", slot, err)
+		}
+	}
+	if err := c.Equip(characterName, item, slot, 1); err != nil {
+		return fmt.Errorf("equipping %s: %w", item.Code, err)
+	}
+	if replaced.Code == "" {
+		return nil
+	}
+
+	if policy.RecycleReplaced && isRecyclable(c.GetItem(replaced.Code)) {
+		if _, err := c.recycle(characterName, replaced.Code, replaced.Quantity); err != nil {
+			return fmt.Errorf("recycling replaced %s: %w", replaced.Code, err)
+		}
+		return nil
+	}
+	if err := c.DepositBank(characterName, InventorySlot{Code: replaced.Code, Quantity: replaced.Quantity}); err != nil {
+		return fmt.Errorf("depositing replaced %s: %w", replaced.Code, err)
+	}
+	c.GetCharacterByName(characterName).WaitForCooldown()
+	return nil
+}
+
+// EquipPendingUpgrades equips the upgrades other characters banked for this one.
+func (c *Svc) EquipPendingUpgrades(characterName string) error {
+	c.AutoEquip.mu.Lock()
+	pending := c.AutoEquip.pending[characterName]
+	delete(c.AutoEquip.pending, characterName)
+	c.AutoEquip.mu.Unlock()
+
+	policy := c.autoEquipPolicy(characterName)
+	for _, code := range pending {
+		item := c.GetItem(code)
+		slot, ok := c.upgradeSlot(c.GetCharacterByName(characterName), item)
+		if !ok {
+			continue
+		}
+		if err := c.equipUpgrade(characterName, item, slot, policy); err != nil {
+			return fmt.Errorf("equipping pending upgrade %s: %w", code, err)
+		}
+	}
+	return nil
+}
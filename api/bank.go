@@ -15,6 +15,20 @@ type GetBankResponse struct {
 	Data []SimpleItem `json:"data"`
 }
 
+type ActionGoldResponse struct {
+	Data ActionGoldData `json:"data"`
+}
+
+type GoldBody struct {
+	Quantity int `json:"quantity"`
+}
+
+type ActionGoldData struct {
+	Cooldown  Cooldown  `json:"cooldown"`
+	Bank      GoldBody  `json:"bank"`
+	Character Character `json:"character"`
+}
+
 type ActionBankData struct {
 	Cooldown  Cooldown      `json:"cooldown"`
 	Item      CraftableItem `json:"item"`
@@ -44,8 +58,8 @@ func (c *Svc) WithdrawFromBankIfFound(characterName, itemCode string, quantity i
 		minQuantity = foundQuantity
 	}
 
-	if minQuantity > c.Characters[characterName].InventoryMaxItems {
-		minQuantity = c.Characters[characterName].InventoryMaxItems
+	if minQuantity > c.GetCharacterByName(characterName).InventoryMaxItems {
+		minQuantity = c.GetCharacterByName(characterName).InventoryMaxItems
 	}
 
 	if err := c.WithdrawBankItem(characterName, itemCode, minQuantity); err != nil {
@@ -85,7 +99,7 @@ func (c *Svc) WithdrawBankItem(characterName, itemCode string, quantity int) err
 	}
 
 	c.updateBank(itemCode, quantity*-1)
-	c.Characters[characterName] = &withdrawResp.Data.Character
+	c.setCharacter(characterName, &withdrawResp.Data.Character)
 	//c.GetCharacterByName(characterName).WaitForCooldown()
 
 	fmt.Println("Withdraw complete")
@@ -123,22 +137,62 @@ func (c *Svc) DepositBank(characterName string, inventoryItem InventorySlot) err
 	c.updateBank(inventoryItem.Code, inventoryItem.Quantity)
 	fmt.Println("Deposit complete")
 
-	c.Characters[characterName] = &bankResp.Data.Character
+	c.setCharacter(characterName, &bankResp.Data.Character)
 	//c.Characters[characterName].WaitForCooldown()
 
 	return nil
 }
 
+// DepositAllItems banks the inventory, holding back what the character's inventory
+// policy keeps, and deposits gold when the policy asks for it.
 func (c *Svc) DepositAllItems(characterName string) error {
-	for _, inventorySlot := range c.Characters[characterName].Inventory {
-		if inventorySlot.Code == "" {
-			break
+	return c.depositAllExcept(characterName, "")
+}
+
+// depositAllExcept is DepositAllItems holding on to every code in the inventory.
+func (c *Svc) depositAllExcept(characterName, code string) error {
+	for _, inventorySlot := range c.depositPlan(characterName) {
+		if inventorySlot.Code == code {
+			continue
 		}
 		if err := c.DepositBank(characterName, inventorySlot); err != nil {
 			return fmt.Errorf("depositing inventorySlot %s: %w", inventorySlot.Code, err)
 		}
-		c.Characters[characterName].WaitForCooldown()
+		c.GetCharacterByName(characterName).WaitForCooldown()
+	}
+
+	if err := c.depositGoldByPolicy(characterName); err != nil {
+		return fmt.Errorf("depositing gold: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Svc) DepositGold(characterName string, quantity int) error {
+	fmt.Printf("%s depositing %d gold in the bank\n", characterName, quantity)
+	if err := c.moveToContent(characterName, "bank"); err != nil {
+		return fmt.Errorf("moving to bank: %w", err)
+	}
+
+	path := fmt.Sprintf("/my/%s/action/bank/deposit/gold", characterName)
+	body := GoldBody{
+		Quantity: quantity,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshalling body: %w", err)
 	}
+	respBytes, err := c.Client.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return fmt.Errorf("executing deposit gold request: %w", err)
+	}
+	goldResp := ActionGoldResponse{}
+	if err := json.Unmarshal(respBytes, &goldResp); err != nil {
+		return fmt.Errorf("unmarshalling gold response: %w", err)
+	}
+
+	c.setCharacter(characterName, &goldResp.Data.Character)
+	fmt.Printf("Bank gold: %d\n", goldResp.Data.Bank.Quantity)
 
 	return nil
 }
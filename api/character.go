@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"reflect"
 	"time"
 )
 
@@ -39,22 +38,22 @@ type Character struct {
 	Cooldown           int       `json:"cooldown"`
 	CooldownExpiration time.Time `json:"cooldown_expiration"` // string<date-time> per docs
 
-	WeaponSlot           string `json:"weapon_slot,omitempty" type:"weapon,armor"`
-	ShieldSlot           string `json:"shield_slot,omitempty" type:"shield,armor"`
-	HelmetSlot           string `json:"helmet_slot,omitempty" type:"helmet,armor"`
-	BodyArmorSlot        string `json:"body_armor_slot,omitempty" type:"body_armor,armor"`
-	LegArmorSlot         string `json:"leg_armor_slot,omitempty" type:"leg_armor,armor"`
-	BootsSlot            string `json:"boots_slot,omitempty" type:"boots,armor"`
-	Ring1Slot            string `json:"ring1_slot,omitempty" type:"ring1,armor"`
-	Ring2Slot            string `json:"ring2_slot,omitempty" type:"ring2,armor"`
-	AmuletSlot           string `json:"amulet_slot,omitempty" type:"amulet,armor"`
-	Artifact1Slot        string `json:"artifact1_slot,omitempty" type:"artifact1,armor"`
-	Artifact2Slot        string `json:"artifact2_slot,omitempty" type:"artifact2,armor"`
-	Artifact3Slot        string `json:"artifact3_slot,omitempty" type:"artifact3,armor"`
-	Utility1Slot         string `json:"utility1_slot,omitempty" type:"utility1,armor"`
-	Utility1SlotQuantity int    `json:"utility1_slot_quantity,omitempty" type:"utility1,armor"`
-	Utility2Slot         string `json:"utility2_slot,omitempty" type:"utility2,armor"`
-	Utility2SlotQuantity int    `json:"utility2_slot_quantity,omitempty" type:"utility2,armor"`
+	WeaponSlot           string `json:"weapon_slot,omitempty"`
+	ShieldSlot           string `json:"shield_slot,omitempty"`
+	HelmetSlot           string `json:"helmet_slot,omitempty"`
+	BodyArmorSlot        string `json:"body_armor_slot,omitempty"`
+	LegArmorSlot         string `json:"leg_armor_slot,omitempty"`
+	BootsSlot            string `json:"boots_slot,omitempty"`
+	Ring1Slot            string `json:"ring1_slot,omitempty"`
+	Ring2Slot            string `json:"ring2_slot,omitempty"`
+	AmuletSlot           string `json:"amulet_slot,omitempty"`
+	Artifact1Slot        string `json:"artifact1_slot,omitempty"`
+	Artifact2Slot        string `json:"artifact2_slot,omitempty"`
+	Artifact3Slot        string `json:"artifact3_slot,omitempty"`
+	Utility1Slot         string `json:"utility1_slot,omitempty"`
+	Utility1SlotQuantity int    `json:"utility1_slot_quantity,omitempty"`
+	Utility2Slot         string `json:"utility2_slot,omitempty"`
+	Utility2SlotQuantity int    `json:"utility2_slot_quantity,omitempty"`
 
 	Task         string `json:"task"`
 	TaskType     string `json:"task_type"`
@@ -77,40 +76,43 @@ type Character struct {
 	DmgAir    int `json:"dmg_air"`
 	ResAir    int `json:"res_air"`
 
-	MiningLevel int `json:"mining_level" skill:"mining"`
+	MiningLevel int `json:"mining_level"`
 	MiningXP    int `json:"mining_xp"`
 	MiningMaxXP int `json:"mining_max_xp"`
 
-	WoodcuttingLevel int `json:"woodcutting_level" skill:"woodcutting"`
+	WoodcuttingLevel int `json:"woodcutting_level"`
 	WoodcuttingXP    int `json:"woodcutting_xp"`
 	WoodcuttingMaxXP int `json:"woodcutting_max_xp"`
 
-	FishingLevel int `json:"fishing_level" skill:"fishing"`
-	FishingXP    int `json:"fising_xp"`
+	FishingLevel int `json:"fishing_level"`
+	FishingXP    int `json:"fishing_xp"`
 	FishingMaxXP int `json:"fishing_max_xp"`
 
-	WeaponcraftingLevel int `json:"weaponcrafting_level" skill:"weaponcrafting"`
+	WeaponcraftingLevel int `json:"weaponcrafting_level"`
 	WeaponcraftingXP    int `json:"weaponcrafting_xp"`
 	WeaponcraftingMaxXP int `json:"weaponcrafting_max_xp"`
 
-	GearcraftingLevel int `json:"gearcrafting_level" skill:"gearcrafting"`
+	GearcraftingLevel int `json:"gearcrafting_level"`
 	GearcraftingXP    int `json:"gearcrafting_xp"`
 	GearcraftingMaxXP int `json:"gearcrafting_max_xp"`
 
-	JewelrycraftingLevel int `json:"jewelrycrafting_level" skill:"jewelrycrafting"`
+	JewelrycraftingLevel int `json:"jewelrycrafting_level"`
 	JewelrycraftingXP    int `json:"jewelrycrafting_xp"`
 	JewelrycraftingMaxXP int `json:"jewelrycrafting_max_xp"`
 
-	CookingLevel int `json:"cooking_level" skill:"cooking"`
+	CookingLevel int `json:"cooking_level"`
 	CookingXP    int `json:"cooking_xp"`
 	CookingMaxXP int `json:"cooking_max_xp"`
 
-	AlchemyLevel int `json:"alchemy_level" skill:"alchemy"`
+	AlchemyLevel int `json:"alchemy_level"`
 	AlchemyXP    int `json:"alchemy_xp"`
 	AlchemyMaxXP int `json:"alchemy_max_xp"`
 
 	InventoryMaxItems int             `json:"inventory_max_items"`
 	Inventory         []InventorySlot `json:"inventory"`
+
+	// clockOffset is how far the server's clock was ahead when the state was stored
+	clockOffset time.Duration
 }
 
 type InventorySlot struct {
@@ -121,7 +123,7 @@ type InventorySlot struct {
 
 func (c *Svc) MoveCharacter(characterName string, x, y int) (*MoveResponse, error) {
 	fmt.Printf("Moving %s to %d, %d\n", characterName, x, y)
-	if c.Characters[characterName].X == x && c.Characters[characterName].Y == y {
+	if character := c.GetCharacterByName(characterName); character.X == x && character.Y == y {
 		fmt.Printf("character already at %d, %d\n", x, y)
 		return nil, nil
 	}
@@ -131,8 +133,8 @@ func (c *Svc) MoveCharacter(characterName string, x, y int) (*MoveResponse, erro
 		return nil, fmt.Errorf("moving character: %w", err)
 	}
 
-	c.Characters[characterName] = &moveResp.Data.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.setCharacter(characterName, &moveResp.Data.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 
 	return moveResp, nil
 }
@@ -167,11 +169,13 @@ func (c *ArtifactsClient) MoveCharacter(name string, x, y int) (*MoveResponse, e
 }
 
 func (c *Character) WaitForCooldown() {
-	if c.CooldownExpiration.Before(time.Now()) {
+	// cooldown expirations are stamped by the server's clock
+	now := time.Now().Add(c.clockOffset)
+	if c.CooldownExpiration.Before(now) {
 		return
 	}
 
-	cooldownTime := c.CooldownExpiration.Sub(time.Now())
+	cooldownTime := c.CooldownExpiration.Sub(now)
 	fmt.Printf("%s on cooldown for %v\n", c.Name, cooldownTime)
 
 	time.Sleep(cooldownTime)
@@ -179,35 +183,11 @@ func (c *Character) WaitForCooldown() {
 	return
 }
 
-func (c Character) AbleToCraft(skill string, wantLevel int) bool {
+func (c Character) AbleToCraft(skill Skill, wantLevel int) bool {
 	if skill == "" || wantLevel == 0 {
 		return true
 	}
-	fields := reflect.TypeOf(c)
-	for i := fields.NumField() - 1; i >= 0; i-- {
-		// run loop backwards because these fields are at the bottom of the object
-		if val, ok := fields.Field(i).Tag.Lookup("skill"); ok && val == skill {
-			level := reflect.ValueOf(c).Field(i).Int()
-			if int(level) < wantLevel {
-				return false
-			}
-			fmt.Println("confirmed character has required level to craft item")
-			return true
-		}
-	}
-	return false
-}
-
-func (c Character) IsEquipped(item CraftableItem) bool {
-	fields := reflect.TypeOf(c)
-	for i := fields.NumField() - 1; i >= 0; i-- {
-		// run loop backwards because these fields are at the bottom of the object
-		if val, ok := fields.Field(i).Tag.Lookup("type"); ok && val == item.Type {
-			fmt.Println("found item equipped to character")
-			return true
-		}
-	}
-	return false
+	return c.SkillLevel(skill) >= wantLevel
 }
 
 func (c Character) FindItemInInventory(code string) (bool, int) {
@@ -220,25 +200,6 @@ func (c Character) FindItemInInventory(code string) (bool, int) {
 	return false, 0
 }
 
-func (c Character) GetAllArmorSlots() []string {
-	fields := reflect.TypeOf(c)
-	out := []string{}
-	for i := 0; i < fields.NumField(); i++ {
-		// run loop backwards because these fields are at the bottom of the object
-		if val, ok := fields.Field(i).Tag.Lookup("type"); ok && val == "armor" {
-			fmt.Println("found item equipped to character")
-			out = append(out, reflect.ValueOf(fields.Field(i)).String())
-		}
-	}
-	return out
-}
-
 func (c Character) IsInventoryFull() bool {
-	runningTotal := 0
-	maxInvWithBuffer := c.InventoryMaxItems - 5
-	for _, item := range c.Inventory {
-		runningTotal += item.Quantity
-	}
-
-	return runningTotal >= maxInvWithBuffer
+	return c.InventoryCount() >= c.InventoryMaxItems-defaultInventoryBuffer
 }
@@ -8,15 +8,33 @@ import (
 	"net/url"
 )
 
+// HTTPError is returned by Do when the server answers with an error status.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("got error response: %d: %s", e.StatusCode, e.Body)
+}
+
 type Client interface {
 	Do(method, path string, params map[string]string, body []byte) ([]byte, error)
+	GetStatus() (*ServerStatus, error)
 
 	GetCharacter(name string) (*CharacterResponse, error)
 	GetCharacters() ([]*Character, error)
+	CreateCharacter(name, skin string) (*Character, error)
+	DeleteCharacter(name string) (*Character, error)
+	GetAccountDetails() (*AccountDetails, error)
+	GetLogs(pageNumber int) (*LogsResponse, error)
+	GetAchievements(account string, pageNumber int) (*AchievementsResponse, error)
+	GetCharacterLeaderboard(sortBy string, pageNumber int) (*CharacterLeaderboardResponse, error)
+	GetAccountLeaderboard(sortBy string, pageNumber int) (*AccountLeaderboardResponse, error)
 	MoveCharacter(name string, x, y int) (*MoveResponse, error)
 
-	Unequip(characterName string, item CraftableItem) (*UnequipData, error)
-	Equip(characterName string, item CraftableItem) (*EquipData, error)
+	Unequip(characterName string, slot Slot, quantity int) (*UnequipData, error)
+	Equip(characterName, code string, slot Slot, quantity int) (*EquipData, error)
 
 	GetItem(code string) (*CraftableItem, error)
 	GetItems(pageNum int) ([]CraftableItem, error)
@@ -27,6 +45,19 @@ type Client interface {
 
 	GetMaps(pageNumber int) ([]Map, error)
 	GetMonsters(pageNumber int) ([]MonsterData, error)
+	GetMonster(code string) (*MonsterData, error)
+	GetActiveEvents(pageNumber int) ([]ActiveEvent, error)
+
+	GetGEOrders(code, orderType string, pageNumber int) (*GEOrdersResponse, error)
+	GetMyGEOrders(pageNumber int) (*GEOrdersResponse, error)
+	GetGEHistory(pageNumber int) (*GEHistoryResponse, error)
+	PlaceGESellOrder(characterName, code string, quantity, price int) (*GEOrderData, error)
+	BuyGEOrder(characterName, id string, quantity int) (*GETransactionData, error)
+	CancelGEOrder(characterName, id string) (*GEOrderData, error)
+
+	GetNPCs(pageNumber int) ([]NPC, error)
+	GetNPCItems(pageNumber int) ([]NPCItem, error)
+	NPCTrade(characterName, side, code string, quantity int) (*NPCTransactionData, error)
 }
 
 type ArtifactsClient struct {
@@ -75,7 +106,7 @@ func (c *ArtifactsClient) Do(method, path string, params map[string]string, body
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("got error response: %d: %s", resp.StatusCode, string(respBytes))
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(respBytes)}
 	}
 
 	return respBytes, nil
@@ -2,9 +2,89 @@ package api
 
 import (
 	"fmt"
+	"math"
+	"sync"
 )
 
+// Reservations tracks the recipe inputs each character is currently crafting with
+// so they aren't recycled or sold from under it.
+type Reservations struct {
+	mu          sync.Mutex
+	ByCharacter map[string]map[string]int
+}
+
+func NewReservations() Reservations {
+	return Reservations{
+		mu:          sync.Mutex{},
+		ByCharacter: make(map[string]map[string]int),
+	}
+}
+
+func (c *Svc) reserveItems(characterName string, items []SimpleItem) {
+	c.Reservations.mu.Lock()
+	defer c.Reservations.mu.Unlock()
+
+	if c.Reservations.ByCharacter[characterName] == nil {
+		c.Reservations.ByCharacter[characterName] = make(map[string]int)
+	}
+	for _, item := range items {
+		c.Reservations.ByCharacter[characterName][item.Code]++
+	}
+}
+
+func (c *Svc) releaseItems(characterName string, items []SimpleItem) {
+	c.Reservations.mu.Lock()
+	defer c.Reservations.mu.Unlock()
+
+	for _, item := range items {
+		c.Reservations.ByCharacter[characterName][item.Code]--
+		if c.Reservations.ByCharacter[characterName][item.Code] <= 0 {
+			delete(c.Reservations.ByCharacter[characterName], item.Code)
+		}
+	}
+}
+
+// reservedItems returns the item codes needed by the character's crafts in progress.
+func (c *Svc) reservedItems(characterName string) map[string]bool {
+	c.Reservations.mu.Lock()
+	defer c.Reservations.mu.Unlock()
+
+	out := map[string]bool{}
+	for code := range c.Reservations.ByCharacter[characterName] {
+		out[code] = true
+	}
+	return out
+}
+
+// accountReservedItems returns the item codes needed by any character's crafts in progress.
+func (c *Svc) accountReservedItems() map[string]bool {
+	c.Reservations.mu.Lock()
+	defer c.Reservations.mu.Unlock()
+
+	out := map[string]bool{}
+	for _, reserved := range c.Reservations.ByCharacter {
+		for code := range reserved {
+			out[code] = true
+		}
+	}
+	return out
+}
+
 func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableItem, error) {
+	item, err := c.craftItem(characterName, code, quantity)
+	if err != nil || item == nil {
+		return item, err
+	}
+
+	if item.IsEquippable() {
+		if err := c.offerUpgrade(characterName, code); err != nil {
+			return nil, fmt.Errorf("offering %s as an upgrade: %w", code, err)
+		}
+	}
+	return item, nil
+}
+
+func (c *Svc) craftItem(characterName, code string, quantity int) (*CraftableItem, error) {
 	fmt.Printf("%s attempting to craft item %s, quantity: %d\n", characterName, code, quantity)
 
 	item := c.GetItem(code)
@@ -16,6 +96,8 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 	if !c.GetCharacterByName(characterName).AbleToCraft(item.Craft.Skill, item.Craft.Level) {
 		return nil, fmt.Errorf("unable to craft item: required level: %d", item.Craft.Level)
 	}
+	c.reserveItems(characterName, item.Craft.Items)
+	defer c.releaseItems(characterName, item.Craft.Items)
 
 	// get dependent items
 	for _, subItem := range item.Craft.Items {
@@ -23,11 +105,10 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 		fmt.Printf("%s needs %d %s to craft %s\n", characterName, remainingQuantity, subItem.Code, code)
 		craftable := c.GetItem(subItem.Code)
 		// check if item equipped
-		if c.GetCharacterByName(characterName).IsEquipped(craftable) {
-			if err := c.Unequip(characterName, craftable); err != nil {
+		if slot, equipped := c.GetCharacterByName(characterName).EquippedSlot(subItem.Code); equipped {
+			if err := c.Unequip(characterName, slot, 1); err != nil {
 				return nil, fmt.Errorf("unequipping item for crafting: %w", err)
 			}
-			continue
 		}
 
 		// check if item in inventory
@@ -42,14 +123,23 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 		if err != nil {
 			return nil, fmt.Errorf("withdrawing %s from bank if found: %w", subItem.Code, err)
 		}
-		c.Characters[characterName].WaitForCooldown()
+		c.GetCharacterByName(characterName).WaitForCooldown()
 		remainingQuantity -= bankQuantity
 
 		if remainingQuantity <= 0 {
 			continue
 		}
 
-		if craftable.Craft != nil && !c.Characters[characterName].AbleToCraft(craftable.Craft.Skill, craftable.Craft.Level) {
+		bought, err := c.buyIfCheaper(characterName, subItem.Code, remainingQuantity)
+		if err != nil {
+			return nil, fmt.Errorf("buying %s: %w", subItem.Code, err)
+		}
+		remainingQuantity -= bought
+		if remainingQuantity <= 0 {
+			continue
+		}
+
+		if craftable.Craft != nil && !c.GetCharacterByName(characterName).AbleToCraft(craftable.Craft.Skill, craftable.Craft.Level) {
 			return nil, fmt.Errorf("unable to craft subitem: %s: needs %s level: %d", craftable.Name, craftable.Craft.Skill, craftable.Craft.Level)
 		}
 
@@ -67,7 +157,7 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 					return nil, fmt.Errorf("%s gathering required item: %s: %w", characterName, craftable.Code, err)
 				}
 			} else {
-				if _, err := c.CraftItem(characterName, craftable.Code, remainingQuantity); err != nil {
+				if _, err := c.craftItem(characterName, craftable.Code, remainingQuantity); err != nil {
 					return nil, fmt.Errorf("%s crafting subitem %s: %w", characterName, craftable.Code, err)
 				}
 			}
@@ -78,7 +168,7 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 
 	var contentCode string
 	if item.Craft != nil {
-		contentCode = item.Craft.Skill
+		contentCode = string(item.Craft.Skill)
 	}
 
 	coords := c.GetCoordinatesByCode(contentCode)
@@ -95,6 +185,71 @@ func (c *Svc) CraftItem(characterName, code string, quantity int) (*CraftableIte
 	return &item, nil
 }
 
+// obtainItem gets quantity of code into the inventory, withdrawing what the bank
+// has and crafting, gathering or fighting for the rest.
+func (c *Svc) obtainItem(characterName, code string, quantity int) error {
+	_, inInventory := c.GetCharacterByName(characterName).FindItemInInventory(code)
+	if inInventory >= quantity {
+		return nil
+	}
+
+	withdrawn, err := c.WithdrawFromBankIfFound(characterName, code, quantity-inInventory)
+	if err != nil {
+		return fmt.Errorf("withdrawing %s: %w", code, err)
+	}
+	c.GetCharacterByName(characterName).WaitForCooldown()
+	missing := quantity - inInventory - withdrawn
+	if missing <= 0 {
+		return nil
+	}
+
+	item := c.GetItem(code)
+	switch {
+	case item.Craft != nil:
+		if _, err := c.craftItem(characterName, code, missing); err != nil {
+			return fmt.Errorf("crafting %s: %w", code, err)
+		}
+	case len(c.GetResourceByCode(code)) > 0:
+		for missing > 0 {
+			resource, _, err := c.SelectResource(characterName, code)
+			if err != nil {
+				return fmt.Errorf("selecting resource: %w", err)
+			}
+			yield := resource.ExpectedYield(code)
+			if yield <= 0 {
+				return fmt.Errorf("%s does not drop %s", resource.Code, code)
+			}
+			actions := int(math.Ceil(float64(missing) / yield))
+			if err := c.Gather(characterName, item, actions); err != nil {
+				return fmt.Errorf("gathering %s: %w", code, err)
+			}
+			// gathering may have banked part of the haul
+			_, have := c.GetCharacterByName(characterName).FindItemInInventory(code)
+			if have < quantity {
+				if _, err := c.WithdrawFromBankIfFound(characterName, code, quantity-have); err != nil {
+					return fmt.Errorf("withdrawing %s: %w", code, err)
+				}
+				c.GetCharacterByName(characterName).WaitForCooldown()
+				_, have = c.GetCharacterByName(characterName).FindItemInInventory(code)
+			}
+			missing = quantity - have
+		}
+	default:
+		want := quantity
+		if err := c.FightForCrafting(characterName, code, &want); err != nil {
+			return fmt.Errorf("fighting for %s: %w", code, err)
+		}
+		_, have := c.GetCharacterByName(characterName).FindItemInInventory(code)
+		if have < quantity {
+			if _, err := c.WithdrawFromBankIfFound(characterName, code, quantity-have); err != nil {
+				return fmt.Errorf("withdrawing %s: %w", code, err)
+			}
+			c.GetCharacterByName(characterName).WaitForCooldown()
+		}
+	}
+	return nil
+}
+
 func (c *Svc) Craft(characterName, code string, quantity int) error {
 	fmt.Printf("%s crafting %s!\n", characterName, code)
 	craftingResp, err := c.Client.CraftItem(characterName, code, quantity)
@@ -102,8 +257,12 @@ func (c *Svc) Craft(characterName, code string, quantity int) error {
 		return fmt.Errorf("crafting item: %w", err)
 	}
 	fmt.Printf("received %v", craftingResp.Details.Items)
-	c.Characters[characterName] = &craftingResp.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.recordActivity(code, quantity, craftingResp.Details, craftingResp.Cooldown)
+	c.setCharacter(characterName, &craftingResp.Character)
+	if item := c.GetItem(code); item.Craft != nil {
+		fmt.Printf("%s %s\n", characterName, c.GetCharacterByName(characterName).SkillSummary(item.Craft.Skill))
+	}
+	c.GetCharacterByName(characterName).WaitForCooldown()
 	return nil
 }
 
@@ -115,7 +274,7 @@ func (c *Svc) GatherLoop(characterName, code string, quantity int) error {
 		return fmt.Errorf("gathering %s: %w", code, err)
 	}
 
-	_, q := c.Characters[characterName].FindItemInInventory(code)
+	_, q := c.GetCharacterByName(characterName).FindItemInInventory(code)
 	inventorySlot := InventorySlot{
 		Code:     code,
 		Quantity: q,
@@ -123,7 +282,7 @@ func (c *Svc) GatherLoop(characterName, code string, quantity int) error {
 	if err := c.DepositBank(characterName, inventorySlot); err != nil {
 		return fmt.Errorf("depositing %d %s: %w", 8, code, err)
 	}
-	c.Characters[characterName].WaitForCooldown()
+	c.GetCharacterByName(characterName).WaitForCooldown()
 
 	//for i := 0; i < quantity; i++ {
 	//	fmt.Printf("gather loop %d\n", i)
@@ -147,45 +306,55 @@ func (c *Svc) GatherLoop(characterName, code string, quantity int) error {
 
 func (c *Svc) Gather(characterName string, item CraftableItem, quantity int) error {
 	fmt.Printf("%s gathering %d %s\n", characterName, quantity, item.Name)
-	resourceData := c.GetResourceByCode(item.Code)
+	resource, coords, err := c.SelectResource(characterName, item.Code)
+	if err != nil {
+		return fmt.Errorf("selecting resource: %w", err)
+	}
+
+	return c.withLoadout(characterName, string(resource.Skill), func() error {
+		return c.gatherAt(characterName, item, *resource, coords, quantity)
+	})
+}
 
+func (c *Svc) gatherAt(characterName string, item CraftableItem, resource ResourceData, coords Coordinates, quantity int) error {
 	fmt.Printf("Gathering %v\n", item)
 	// find location of item
-	coords := c.GetCoordinatesByCode(resourceData[0].Code)
-	if _, err := c.MoveCharacter(characterName, coords[0].X, coords[0].Y); err != nil {
-		return fmt.Errorf("moving to bank: %w", err)
+	if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+		return fmt.Errorf("moving to resource: %w", err)
 	}
 
 	for i := 0; i < quantity; i++ {
-		if c.GetCharacterByName(characterName).IsInventoryFull() {
-			if err := c.DepositAllItems(characterName); err != nil {
-				return fmt.Errorf("depositing inventory: %w", err)
-			}
-
+		deposited, err := c.makeRoomFor(characterName, resource.Drops)
+		if err != nil {
+			return fmt.Errorf("making room to gather %s: %w", item.Name, err)
+		}
+		if deposited {
 			// find location of item
-			if _, err := c.MoveCharacter(characterName, coords[0].X, coords[0].Y); err != nil {
-				return fmt.Errorf("moving to bank: %w", err)
+			if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+				return fmt.Errorf("moving to resource: %w", err)
 			}
 		}
 
 		// gather item
-		if err := c.gather(characterName); err != nil {
+		if err := c.gather(characterName, item.Code); err != nil {
 			return fmt.Errorf("attempting to gather %s #%d: %w", item.Name, i, err)
 		}
 	}
+	fmt.Printf("%s %s\n", characterName, c.GetCharacterByName(characterName).SkillSummary(resource.Skill))
 
 	return nil
 }
 
-func (c *Svc) gather(characterName string) error {
+func (c *Svc) gather(characterName, code string) error {
 	gatherResp, err := c.Client.Gather(characterName)
 	if err != nil {
 		return fmt.Errorf("gathering: %w", err)
 	}
 	fmt.Printf("received %v", gatherResp.Details.Items)
+	c.recordActivity(code, 1, gatherResp.Details, gatherResp.Cooldown)
 
-	c.Characters[characterName] = &gatherResp.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.setCharacter(characterName, &gatherResp.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 
 	return nil
 }
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EconomyPolicy lets the crafting planner buy missing inputs from NPCs or the Grand
+// Exchange when that is cheaper than the time it takes to produce them.
+type EconomyPolicy struct {
+	Enabled bool
+	// GoldPerHour is what an hour of a character's time is worth.
+	GoldPerHour float64
+	// GoldBudget caps the gold spent on inputs across the account. Zero means no cap
+	// beyond the gold the buying character carries.
+	GoldBudget int
+}
+
+type Economy struct {
+	mu     sync.Mutex
+	Policy EconomyPolicy
+	spent  int
+}
+
+func NewEconomy() Economy {
+	return Economy{
+		mu: sync.Mutex{},
+	}
+}
+
+func (c *Svc) SetEconomyPolicy(policy EconomyPolicy) {
+	c.Economy.mu.Lock()
+	defer c.Economy.mu.Unlock()
+	c.Economy.Policy = policy
+}
+
+// GoldSpentOnInputs returns the gold spent buying crafting inputs this session.
+func (c *Svc) GoldSpentOnInputs() int {
+	c.Economy.mu.Lock()
+	defer c.Economy.mu.Unlock()
+	return c.Economy.spent
+}
+
+// timeValue returns the gold the time needed to produce quantity of code is worth,
+// ignoring the bank since the planner has already withdrawn what it holds.
+func (c *Svc) timeValue(characterName, code string, quantity int, goldPerHour float64) float64 {
+	seconds := c.obtainSeconds(c.GetCharacterByName(characterName), code, quantity, map[string]int{}, 0)
+	return seconds / 3600 * goldPerHour
+}
+
+// buyIfCheaper buys quantity of code from an NPC or the Grand Exchange, whichever is
+// cheaper, when the economy policy values the time to produce it above its price and
+// the budget allows it. It returns how many were bought.
+func (c *Svc) buyIfCheaper(characterName, code string, quantity int) (int, error) {
+	c.Economy.mu.Lock()
+	policy := c.Economy.Policy
+	c.Economy.mu.Unlock()
+	if !policy.Enabled {
+		return 0, nil
+	}
+
+	var fills []geFill
+	cost := 0
+	if c.GetItem(code).Tradeable {
+		var err error
+		if fills, cost, err = c.planGEBuy(code, quantity); err != nil {
+			return 0, fmt.Errorf("pricing %s: %w", code, err)
+		}
+	}
+	offer, fromNPC := c.bestNPCOffer(code, npcSideBuy)
+	if fromNPC && len(fills) > 0 && cost <= offer.BuyPrice*quantity {
+		fromNPC = false
+	}
+	if fromNPC {
+		cost = offer.BuyPrice * quantity
+	}
+	if cost == 0 {
+		return 0, nil
+	}
+
+	if c.GetCharacterByName(characterName).Gold < cost {
+		return 0, nil
+	}
+	if value := c.timeValue(characterName, code, quantity, policy.GoldPerHour); float64(cost) >= value {
+		return 0, nil
+	}
+
+	// reserve the cost so concurrent purchases can't overrun the budget together
+	c.Economy.mu.Lock()
+	if policy.GoldBudget > 0 && c.Economy.spent+cost > policy.GoldBudget {
+		c.Economy.mu.Unlock()
+		fmt.Printf("Buying %d %s for %d gold would exceed the budget\n", quantity, code, cost)
+		return 0, nil
+	}
+	c.Economy.spent += cost
+	c.Economy.mu.Unlock()
+
+	bought, paid := 0, 0
+	defer func() {
+		// release what wasn't spent
+		c.Economy.mu.Lock()
+		c.Economy.spent -= cost - paid
+		c.Economy.mu.Unlock()
+	}()
+	if fromNPC {
+		transaction, err := c.tradeWithNPC(characterName, offer.NPC, npcSideBuy, code, quantity)
+		if err != nil {
+			return 0, fmt.Errorf("buying %s from %s: %w", code, offer.NPC, err)
+		}
+		bought, paid = transaction.Quantity, transaction.TotalPrice
+	} else {
+		for _, fill := range fills {
+			transaction, err := c.BuyOnGE(characterName, fill.Order.ID, fill.Quantity)
+			if err != nil {
+				// the order may have been taken meanwhile, keep what was bought
+				fmt.Printf("Buying %s from order %s: %v\n", code, fill.Order.ID, err)
+				break
+			}
+			bought += transaction.Quantity
+			paid += transaction.TotalPrice
+		}
+	}
+
+	fmt.Printf("%s bought %d %s for %d gold\n", characterName, bought, code, paid)
+	return bought, nil
+}
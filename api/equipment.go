@@ -41,33 +41,173 @@ type EquipData struct {
 	Character Character     `json:"character"`
 }
 
-func (c *Svc) Unequip(characterName string, item CraftableItem) error {
-	fmt.Printf("Unquipping item: %s\n", item.Name)
-	unequipResp, err := c.Client.Unequip(characterName, item)
+type Slot string
+
+const (
+	SlotWeapon    Slot = "weapon"
+	SlotShield    Slot = "shield"
+	SlotHelmet    Slot = "helmet"
+	SlotBodyArmor Slot = "body_armor"
+	SlotLegArmor  Slot = "leg_armor"
+	SlotBoots     Slot = "boots"
+	SlotRing1     Slot = "ring1"
+	SlotRing2     Slot = "ring2"
+	SlotAmulet    Slot = "amulet"
+	SlotArtifact1 Slot = "artifact1"
+	SlotArtifact2 Slot = "artifact2"
+	SlotArtifact3 Slot = "artifact3"
+	SlotUtility1  Slot = "utility1"
+	SlotUtility2  Slot = "utility2"
+)
+
+var Slots = []Slot{
+	SlotWeapon,
+	SlotShield,
+	SlotHelmet,
+	SlotBodyArmor,
+	SlotLegArmor,
+	SlotBoots,
+	SlotRing1,
+	SlotRing2,
+	SlotAmulet,
+	SlotArtifact1,
+	SlotArtifact2,
+	SlotArtifact3,
+	SlotUtility1,
+	SlotUtility2,
+}
+
+// slotsByItemType maps an item type to the slots able to hold it.
+var slotsByItemType = map[string][]Slot{
+	"weapon":     {SlotWeapon},
+	"shield":     {SlotShield},
+	"helmet":     {SlotHelmet},
+	"body_armor": {SlotBodyArmor},
+	"leg_armor":  {SlotLegArmor},
+	"boots":      {SlotBoots},
+	"ring":       {SlotRing1, SlotRing2},
+	"amulet":     {SlotAmulet},
+	"artifact":   {SlotArtifact1, SlotArtifact2, SlotArtifact3},
+	"utility":    {SlotUtility1, SlotUtility2},
+}
+
+// SlotsForItemType returns the candidate slots for an item type, or nil if the
+// item cannot be equipped.
+func SlotsForItemType(itemType string) []Slot {
+	return slotsByItemType[itemType]
+}
+
+func (i CraftableItem) IsEquippable() bool {
+	return len(SlotsForItemType(i.Type)) > 0
+}
+
+// EquippedItem returns the item held in slot. Code is empty when the slot is free.
+func (c Character) EquippedItem(slot Slot) SimpleItem {
+	var code string
+	quantity := 1
+	switch slot {
+	case SlotWeapon:
+		code = c.WeaponSlot
+	case SlotShield:
+		code = c.ShieldSlot
+	case SlotHelmet:
+		code = c.HelmetSlot
+	case SlotBodyArmor:
+		code = c.BodyArmorSlot
+	case SlotLegArmor:
+		code = c.LegArmorSlot
+	case SlotBoots:
+		code = c.BootsSlot
+	case SlotRing1:
+		code = c.Ring1Slot
+	case SlotRing2:
+		code = c.Ring2Slot
+	case SlotAmulet:
+		code = c.AmuletSlot
+	case SlotArtifact1:
+		code = c.Artifact1Slot
+	case SlotArtifact2:
+		code = c.Artifact2Slot
+	case SlotArtifact3:
+		code = c.Artifact3Slot
+	case SlotUtility1:
+		code, quantity = c.Utility1Slot, c.Utility1SlotQuantity
+	case SlotUtility2:
+		code, quantity = c.Utility2Slot, c.Utility2SlotQuantity
+	}
+
+	if code == "" {
+		return SimpleItem{}
+	}
+	return SimpleItem{Code: code, Quantity: quantity}
+}
+
+// EquippedItems returns every occupied slot and what it holds.
+func (c Character) EquippedItems() map[Slot]SimpleItem {
+	out := make(map[Slot]SimpleItem)
+	for _, slot := range Slots {
+		if item := c.EquippedItem(slot); item.Code != "" {
+			out[slot] = item
+		}
+	}
+	return out
+}
+
+// EquippedSlot returns the first slot holding code. An empty code is never equipped.
+func (c Character) EquippedSlot(code string) (Slot, bool) {
+	if code == "" {
+		return "", false
+	}
+	for _, slot := range Slots {
+		if c.EquippedItem(slot).Code == code {
+			return slot, true
+		}
+	}
+	return "", false
+}
+
+func (c Character) IsEquipped(code string) bool {
+	_, found := c.EquippedSlot(code)
+	return found
+}
+
+// FreeSlotFor returns an empty slot able to hold item.
+func (c Character) FreeSlotFor(item CraftableItem) (Slot, bool) {
+	for _, slot := range SlotsForItemType(item.Type) {
+		if c.EquippedItem(slot).Code == "" {
+			return slot, true
+		}
+	}
+	return "", false
+}
+
+func (c *Svc) Unequip(characterName string, slot Slot, quantity int) error {
+	fmt.Printf("%s unequipping slot: %s\n", characterName, slot)
+	unequipResp, err := c.Client.Unequip(characterName, slot, quantity)
 	if err != nil {
-		return fmt.Errorf("unequipping item: %w", err)
+		return fmt.Errorf("unequipping %s: %w", slot, err)
 	}
-	c.Characters[characterName] = &unequipResp.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.setCharacter(characterName, &unequipResp.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 	return nil
 }
 
-func (c *Svc) Equip(characterName string, item CraftableItem) error {
-	fmt.Printf("Equipping item: %s\n", item.Name)
-	equipResp, err := c.Client.Equip(characterName, item)
+func (c *Svc) Equip(characterName string, item CraftableItem, slot Slot, quantity int) error {
+	fmt.Printf("%s equipping item %s in slot %s\n", characterName, item.Name, slot)
+	equipResp, err := c.Client.Equip(characterName, item.Code, slot, quantity)
 	if err != nil {
 		return fmt.Errorf("equipping item: %w", err)
 	}
-	c.Characters[characterName] = &equipResp.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.setCharacter(characterName, &equipResp.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 	return nil
 }
 
-func (c *ArtifactsClient) Unequip(characterName string, item CraftableItem) (*UnequipData, error) {
+func (c *ArtifactsClient) Unequip(characterName string, slot Slot, quantity int) (*UnequipData, error) {
 	path := fmt.Sprintf("/my/%s/action/unequip", characterName)
 	bodyStruct := UnequipBody{
-		Slot:     item.Type,
-		Quantity: 1,
+		Slot:     string(slot),
+		Quantity: quantity,
 	}
 
 	bodyBytes, err := json.Marshal(bodyStruct)
@@ -77,7 +217,7 @@ func (c *ArtifactsClient) Unequip(characterName string, item CraftableItem) (*Un
 
 	resp, err := c.Do(http.MethodPost, path, nil, bodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("executing gather request: %w", err)
+		return nil, fmt.Errorf("executing unequip request: %w", err)
 	}
 
 	unequipResp := UnequipResponse{}
@@ -88,13 +228,12 @@ func (c *ArtifactsClient) Unequip(characterName string, item CraftableItem) (*Un
 	return &unequipResp.Data, nil
 }
 
-func (c *ArtifactsClient) Equip(characterName string, item CraftableItem) (*EquipData, error) {
-	fmt.Printf("Equipping item: %s\n", item.Name)
+func (c *ArtifactsClient) Equip(characterName, code string, slot Slot, quantity int) (*EquipData, error) {
 	path := fmt.Sprintf("/my/%s/action/equip", characterName)
 	bodyStruct := EquipBody{
-		Slot:     item.Type,
-		Quantity: 1,
-		Code:     item.Code,
+		Slot:     string(slot),
+		Quantity: quantity,
+		Code:     code,
 	}
 
 	bodyBytes, err := json.Marshal(bodyStruct)
@@ -104,7 +243,7 @@ func (c *ArtifactsClient) Equip(characterName string, item CraftableItem) (*Equi
 
 	resp, err := c.Do(http.MethodPost, path, nil, bodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("executing gather request: %w", err)
+		return nil, fmt.Errorf("executing equip request: %w", err)
 	}
 
 	equipResp := EquipResponse{}
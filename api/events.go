@@ -0,0 +1,286 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type ActiveEvent struct {
+	Name         string    `json:"name"`
+	Code         string    `json:"code"`
+	Map          Map       `json:"map"`
+	PreviousSkin string    `json:"previous_skin"`
+	Duration     int       `json:"duration"`
+	Expiration   time.Time `json:"expiration"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ActiveEventsResponse struct {
+	Data  []ActiveEvent `json:"data"`
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+	Size  int           `json:"size"`
+	Pages int           `json:"pages"`
+}
+
+type SingleMonsterResponse struct {
+	Data  MonsterData  `json:"data"`
+	Error ErrorMessage `json:"error"`
+}
+
+// EventNotification is sent when an event starts or ends.
+type EventNotification struct {
+	Event   ActiveEvent
+	Started bool
+}
+
+// trackedEvent remembers what an event added to the catalog so it can be undone.
+type trackedEvent struct {
+	event        ActiveEvent
+	addedTile    bool
+	addedMonster bool
+	// hidden are the content codes the event replaced on its tile
+	hidden []string
+}
+
+type Events struct {
+	mu       sync.Mutex
+	active   map[string]trackedEvent
+	handlers []func(EventNotification)
+}
+
+func NewEvents() Events {
+	return Events{
+		mu:     sync.Mutex{},
+		active: make(map[string]trackedEvent),
+	}
+}
+
+func (e ActiveEvent) key() string {
+	return fmt.Sprintf("%s@%d,%d", e.Code, e.Map.X, e.Map.Y)
+}
+
+func (c *ArtifactsClient) GetActiveEvents(pageNumber int) ([]ActiveEvent, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/events/active", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing GetActiveEvents request: %w", err)
+	}
+	eventsResp := ActiveEventsResponse{}
+	if err := json.Unmarshal(respBytes, &eventsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling body: %w", err)
+	}
+	return eventsResp.Data, nil
+}
+
+func (c *ArtifactsClient) GetMonster(code string) (*MonsterData, error) {
+	respBytes, err := c.Do(http.MethodGet, fmt.Sprintf("/monsters/%s", code), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing get monster request: %w", err)
+	}
+	monsterResp := SingleMonsterResponse{}
+	if err := json.Unmarshal(respBytes, &monsterResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp: %w", err)
+	}
+	if monsterResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", monsterResp.Error.Code, monsterResp.Error.Message)
+	}
+	return &monsterResp.Data, nil
+}
+
+// OnEvent registers fn to be called whenever an event starts or ends.
+func (c *Svc) OnEvent(fn func(EventNotification)) {
+	c.Events.mu.Lock()
+	defer c.Events.mu.Unlock()
+	c.Events.handlers = append(c.Events.handlers, fn)
+}
+
+// ActiveEvents returns the events currently known to be running.
+func (c *Svc) ActiveEvents() []ActiveEvent {
+	c.Events.mu.Lock()
+	defer c.Events.mu.Unlock()
+
+	events := make([]ActiveEvent, 0, len(c.Events.active))
+	for _, tracked := range c.Events.active {
+		events = append(events, tracked.event)
+	}
+	return events
+}
+
+// StartEventPoller refreshes the active events every interval in the background
+// until the returned stop function is called.
+func (c *Svc) StartEventPoller(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.RefreshEvents(); err != nil {
+				fmt.Printf("Refreshing events: %v\n", err)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+// RefreshEvents fetches the active events, adds the content of new ones to the map
+// and monster catalogs and removes the content of those that ended.
+func (c *Svc) RefreshEvents() error {
+	events := []ActiveEvent{}
+	for i := 1; i < 100; i++ {
+		page, err := c.Client.GetActiveEvents(i)
+		if err != nil {
+			return fmt.Errorf("getting active events page %d: %w", i, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		events = append(events, page...)
+	}
+
+	now := c.serverNow()
+	running := map[string]ActiveEvent{}
+	for _, event := range events {
+		if event.Expiration.IsZero() || event.Expiration.After(now) {
+			running[event.key()] = event
+		}
+	}
+
+	c.Events.mu.Lock()
+	notifications := []EventNotification{}
+	for key, tracked := range c.Events.active {
+		if _, ok := running[key]; ok {
+			continue
+		}
+		c.removeEventContent(tracked)
+		delete(c.Events.active, key)
+		notifications = append(notifications, EventNotification{Event: tracked.event})
+	}
+	for key, event := range running {
+		if _, ok := c.Events.active[key]; ok {
+			continue
+		}
+		tracked, err := c.addEventContent(event)
+		if err != nil {
+			c.Events.mu.Unlock()
+			return fmt.Errorf("adding event %s: %w", event.Code, err)
+		}
+		c.Events.active[key] = tracked
+		notifications = append(notifications, EventNotification{Event: event, Started: true})
+	}
+	handlers := c.Events.handlers
+	c.Events.mu.Unlock()
+
+	for _, notification := range notifications {
+		c.notifyEvent(notification, handlers)
+	}
+	return nil
+}
+
+func (c *Svc) notifyEvent(notification EventNotification, handlers []func(EventNotification)) {
+	event := notification.Event
+	if notification.Started {
+		fmt.Printf("Event started: %s at (%d,%d) until %s\n", event.Name, event.Map.X, event.Map.Y, event.Expiration.Format(time.Kitchen))
+	} else {
+		fmt.Printf("Event ended: %s at (%d,%d)\n", event.Name, event.Map.X, event.Map.Y)
+	}
+	for _, handler := range handlers {
+		handler(notification)
+	}
+}
+
+func (c *Svc) addEventContent(event ActiveEvent) (trackedEvent, error) {
+	tracked := trackedEvent{event: event}
+	content := event.Map.Content
+	if content.Code == "" {
+		return tracked, nil
+	}
+
+	var monster *MonsterData
+	if content.Type == "monster" {
+		if _, known := c.GetMonster(content.Code); !known {
+			var err error
+			if monster, err = c.Client.GetMonster(content.Code); err != nil {
+				return tracked, fmt.Errorf("getting monster %s: %w", content.Code, err)
+			}
+		}
+	}
+
+	coords := Coordinates{event.Map.X, event.Map.Y}
+	c.mapsMu.Lock()
+	defer c.mapsMu.Unlock()
+	// the event replaces whatever was on the tile until it ends
+	for code := range c.MapsByCode {
+		if code != content.Code && c.removeTile(code, coords) {
+			tracked.hidden = append(tracked.hidden, code)
+		}
+	}
+	if indexOfCoordinates(c.MapsByCode[content.Code], coords) < 0 {
+		c.MapsByCode[content.Code] = append(c.MapsByCode[content.Code], coords)
+		tracked.addedTile = true
+	}
+	if monster != nil {
+		c.indexMonster(*monster)
+		tracked.addedMonster = true
+	}
+	return tracked, nil
+}
+
+func (c *Svc) removeEventContent(tracked trackedEvent) {
+	content := tracked.event.Map.Content
+	coords := Coordinates{tracked.event.Map.X, tracked.event.Map.Y}
+	c.mapsMu.Lock()
+	defer c.mapsMu.Unlock()
+
+	if tracked.addedTile {
+		c.removeTile(content.Code, coords)
+	}
+	for _, code := range tracked.hidden {
+		if indexOfCoordinates(c.MapsByCode[code], coords) < 0 {
+			c.MapsByCode[code] = append(c.MapsByCode[code], coords)
+		}
+	}
+	if tracked.addedMonster {
+		c.unindexMonster(content.Code)
+	}
+}
+
+// removeTile drops coords from the tiles of code and reports whether it was there.
+// The caller holds mapsMu.
+func (c *Svc) removeTile(code string, coords Coordinates) bool {
+	tiles := c.MapsByCode[code]
+	i := indexOfCoordinates(tiles, coords)
+	if i < 0 {
+		return false
+	}
+	c.MapsByCode[code] = append(tiles[:i:i], tiles[i+1:]...)
+	if len(c.MapsByCode[code]) == 0 {
+		delete(c.MapsByCode, code)
+	}
+	return true
+}
+
+func indexOfCoordinates(tiles []Coordinates, coords Coordinates) int {
+	for i, tile := range tiles {
+		if tile == coords {
+			return i
+		}
+	}
+	return -1
+}
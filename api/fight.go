@@ -3,9 +3,12 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 )
 
+const maxFightTurns = 100
+
 type FightResponse struct {
 	Data  FightData    `json:"data"`
 	Error ErrorMessage `json:"error"`
@@ -37,13 +40,14 @@ type BlockedHits struct {
 }
 
 func (c *Svc) Fight(characterName string) (*FightResponse, error) {
-	percentHealth := float64(c.Characters[characterName].Hp) / float64(c.Characters[characterName].MaxHP) * 100.0
-	if percentHealth < 25 {
-		fmt.Printf("Character HP below 25 percent: %.2f\n", percentHealth)
-		return nil, nil
+	if c.needsHealing(characterName) {
+		if err := c.Heal(characterName); err != nil {
+			return nil, fmt.Errorf("healing before fight: %w", err)
+		}
 	}
 
 	fmt.Println("Fighting!")
+	before := *c.GetCharacterByName(characterName)
 	path := fmt.Sprintf("/my/%s/action/fight", characterName)
 	respBytes, err := c.Client.Do(http.MethodPost, path, nil, nil)
 	if err != nil {
@@ -59,32 +63,57 @@ func (c *Svc) Fight(characterName string) (*FightResponse, error) {
 		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", fightResp.Error.Code, fightResp.Error.Message)
 	}
 
-	c.Characters[characterName] = &fightResp.Data.Character
+	c.setCharacter(characterName, &fightResp.Data.Character)
+	c.recordUtilityUse(characterName, before, fightResp.Data.Character)
 	fmt.Printf("Result: %s\n", fightResp.Data.Fight.Result)
 	fmt.Printf("XP Gained: %d\n", fightResp.Data.Fight.Xp)
 	fmt.Printf("Character level: %d\n", fightResp.Data.Character.Level)
-	fmt.Printf("XP to level: %d\n", fightResp.Data.Character.MaxXP-fightResp.Data.Character.XP)
+	fmt.Printf("XP to level: %d\n", fightResp.Data.Character.XPToNextLevel(SkillCombat))
 	fmt.Printf("Drops received: %v\n", fightResp.Data.Fight.Drops)
 	fmt.Printf("Gold received: %v\n", fightResp.Data.Fight.Gold)
 	fmt.Printf("Character HP: %d\n", fightResp.Data.Character.Hp)
 	fmt.Printf("Cooldown: %d seconds\n", fightResp.Data.Cooldown.TotalSeconds)
 
-	c.Characters[characterName].WaitForCooldown()
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	for _, drop := range fightResp.Data.Fight.Drops {
+		if !c.GetItem(drop.Code).IsEquippable() {
+			continue
+		}
+		if err := c.offerUpgrade(characterName, drop.Code); err != nil {
+			return nil, fmt.Errorf("offering %s as an upgrade: %w", drop.Code, err)
+		}
+	}
 
 	return &fightResp, nil
 }
 
 func (c *Svc) ContinuousFightLoop(characterName string) error {
-	percentHealth := float64(c.Characters[characterName].Hp) / float64(c.Characters[characterName].MaxHP) * 100.0
+	done, err := c.startLoop(characterName)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if err := c.EquipPendingUpgrades(characterName); err != nil {
+		return fmt.Errorf("equipping pending upgrades: %w", err)
+	}
+
+	// the loadout switch may visit the bank so remember where the fight is
 	coords := Coordinates{c.GetCharacterByName(characterName).X, c.GetCharacterByName(characterName).Y}
-	if percentHealth < 25 {
-		fmt.Printf("Character HP below 25 percent: %.2f, HP: %d MaxHP: %d\n", percentHealth, c.Characters[characterName].Hp, c.Characters[characterName].MaxHP)
-		if err := c.Rest(characterName); err != nil {
-			return fmt.Errorf("executing rest request: %w", err)
-		}
-		if err := c.ContinuousFightLoop(characterName); err != nil {
-			return fmt.Errorf("recursive rest fightloop: %w", err)
+	return c.withLoadout(characterName, LoadoutCombat, func() error {
+		if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+			return fmt.Errorf("moving back to fight: %w", err)
 		}
+		return c.continuousFightLoop(characterName, coords)
+	})
+}
+
+// continuousFightLoop fights at coords until an error. The coordinates are passed
+// down because healing, banking and upgrades move the character away between fights.
+func (c *Svc) continuousFightLoop(characterName string, coords Coordinates) error {
+	if err := c.prepareForFight(characterName, coords); err != nil {
+		return fmt.Errorf("preparing for fight: %w", err)
 	}
 
 	fightResp, err := c.Fight(characterName)
@@ -98,8 +127,7 @@ func (c *Svc) ContinuousFightLoop(characterName string) error {
 		}
 	}
 
-	c.Characters[characterName] = &fightResp.Data.Character
-	if err := c.ContinuousFightLoop(characterName); err != nil {
+	if err := c.continuousFightLoop(characterName, coords); err != nil {
 		return fmt.Errorf("recursive fightloop: %w", err)
 	}
 
@@ -107,6 +135,16 @@ func (c *Svc) ContinuousFightLoop(characterName string) error {
 }
 
 func (c *Svc) FightForCrafting(characterName, dropCode string, quantity *int) error {
+	done, err := c.startLoop(characterName)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if err := c.EquipPendingUpgrades(characterName); err != nil {
+		return fmt.Errorf("equipping pending upgrades: %w", err)
+	}
+
 	//maxLevel := c.Characters[characterName].Level
 	monsters := c.GetMonsterByDrop(dropCode)
 
@@ -117,6 +155,10 @@ func (c *Svc) FightForCrafting(characterName, dropCode string, quantity *int) er
 		//if monster.Level > maxLevel {
 		//	continue
 		//}
+		// event monsters only have tiles while the event runs
+		if len(c.GetCoordinatesByCode(monster.Code)) == 0 {
+			continue
+		}
 		for _, drop := range monster.Drops {
 			if drop.Code != dropCode {
 				continue
@@ -128,26 +170,35 @@ func (c *Svc) FightForCrafting(characterName, dropCode string, quantity *int) er
 		}
 	}
 
-	// find selected monster
-	coords := c.GetCoordinatesByCode(bestMonsterCode)
-	if _, err := c.MoveCharacter(characterName, coords[0].X, coords[0].Y); err != nil {
-		return fmt.Errorf("moving to bank: %w", err)
+	if bestMonsterCode == "" {
+		return fmt.Errorf("no monster on the map drops %s", dropCode)
 	}
 
 	wantQuantity := 1000
 	if quantity != nil {
 		wantQuantity = *quantity
 	}
-	if err := c.ContinuousFightLoopForCrafting(characterName, dropCode, wantQuantity); err != nil {
-		return fmt.Errorf("ContinuousFightLoopForCrafting: %w", err)
-	}
+	return c.withLoadout(characterName, LoadoutCombat, func() error {
+		// find selected monster
+		coords := c.GetCoordinatesByCode(bestMonsterCode)
+		if _, err := c.MoveCharacter(characterName, coords[0].X, coords[0].Y); err != nil {
+			return fmt.Errorf("moving to bank: %w", err)
+		}
 
-	return nil
+		if err := c.ContinuousFightLoopForCrafting(characterName, dropCode, wantQuantity); err != nil {
+			return fmt.Errorf("ContinuousFightLoopForCrafting: %w", err)
+		}
+		return nil
+	})
 }
 
 func (c *Svc) ContinuousFightLoopForCrafting(characterName, dropCode string, wantQuantity int) error {
 	// note coordinates to prepare for loss
 	coords := Coordinates{c.GetCharacterByName(characterName).X, c.GetCharacterByName(characterName).Y}
+	return c.continuousFightLoopForCrafting(characterName, dropCode, wantQuantity, coords)
+}
+
+func (c *Svc) continuousFightLoopForCrafting(characterName, dropCode string, wantQuantity int, coords Coordinates) error {
 	// if we have the quantity we want, stop
 	runningTotal := 0
 
@@ -160,30 +211,23 @@ func (c *Svc) ContinuousFightLoopForCrafting(characterName, dropCode string, wan
 	c.Bank.mu.Unlock()
 
 	// check inventory
-	_, invQuantity := c.Characters[characterName].FindItemInInventory(dropCode)
+	_, invQuantity := c.GetCharacterByName(characterName).FindItemInInventory(dropCode)
 	runningTotal += invQuantity
 
 	if runningTotal >= wantQuantity {
 		fmt.Printf("Goal reached. Ending loop...\n")
+		c.printUtilityReport(characterName)
 		return nil
 	}
 
-	percentHealth := float64(c.Characters[characterName].Hp) / float64(c.Characters[characterName].MaxHP) * 100.0
-	if percentHealth < 25 {
-		fmt.Printf("%s HP below 25 percent: %.2f, HP: %d MaxHP: %d\n", characterName, percentHealth, c.Characters[characterName].Hp, c.Characters[characterName].MaxHP)
-		if err := c.Rest(characterName); err != nil {
-			return fmt.Errorf("executing rest request: %w", err)
-		}
-		//if err := c.ContinuousFightLoopForCrafting(characterName, dropCode, wantQuantity); err != nil {
-		//	return fmt.Errorf("recursive rest fightloop: %w", err)
-		//}
+	if err := c.prepareForFight(characterName, coords); err != nil {
+		return fmt.Errorf("preparing for fight: %w", err)
 	}
 
 	fightResp, err := c.Fight(characterName)
 	if err != nil {
 		return fmt.Errorf("executing fight request: %w", err)
 	}
-	c.Characters[characterName] = &fightResp.Data.Character
 
 	if fightResp.Data.Fight.Result == "loss" {
 		fmt.Println("Character lost, moving back to monster spawn")
@@ -192,15 +236,74 @@ func (c *Svc) ContinuousFightLoopForCrafting(characterName, dropCode string, wan
 		}
 	}
 
-	if c.Characters[characterName].IsInventoryFull() {
-		if err := c.DepositAllItems(characterName); err != nil {
-			return fmt.Errorf("depositing all items: %w", err)
+	if err := c.continuousFightLoopForCrafting(characterName, dropCode, wantQuantity, coords); err != nil {
+		return fmt.Errorf("recursive fightloop: %w", err)
+	}
+
+	return nil
+}
+
+// prepareForFight heals, banks and restocks consumables as needed, then returns the
+// character to the fight at coords.
+func (c *Svc) prepareForFight(characterName string, coords Coordinates) error {
+	if c.needsHealing(characterName) {
+		if err := c.Heal(characterName); err != nil {
+			return fmt.Errorf("healing: %w", err)
 		}
 	}
 
-	if err := c.ContinuousFightLoopForCrafting(characterName, dropCode, wantQuantity); err != nil {
-		return fmt.Errorf("recursive fightloop: %w", err)
+	if _, err := c.makeRoomFor(characterName, c.dropsAt(coords)); err != nil {
+		return fmt.Errorf("making room for drops: %w", err)
+	}
+	if err := c.StockUtilities(characterName); err != nil {
+		return fmt.Errorf("stocking utilities: %w", err)
+	}
+	if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+		return fmt.Errorf("moving back to fight: %w", err)
 	}
 
 	return nil
 }
+
+// FightEstimate is the predicted outcome of a fight at full hp.
+type FightEstimate struct {
+	Win bool
+	// CharacterTurns is how many hits the character needs to kill the monster.
+	CharacterTurns int
+	// MonsterTurns is how many hits the monster needs to kill the character.
+	MonsterTurns int
+}
+
+// EstimateFight predicts whether the character beats monsterCode from its elemental
+// attack, damage and resistance stats. The character strikes first and fights end
+// after maxFightTurns turns.
+func (c *Svc) EstimateFight(characterName, monsterCode string) (*FightEstimate, error) {
+	monster, found := c.GetMonster(monsterCode)
+	if !found {
+		return nil, fmt.Errorf("unknown monster %s", monsterCode)
+	}
+	character := c.GetCharacterByName(characterName)
+
+	characterDamage, monsterDamage := 0, 0
+	for _, element := range elements {
+		attack, dmg, res := character.elementStats(element)
+		monsterAttack, monsterRes := monster.elementStats(element)
+		characterDamage += int(math.Round(float64(attack) * (1 + float64(dmg)/100) * (1 - float64(monsterRes)/100)))
+		monsterDamage += int(math.Round(float64(monsterAttack) * (1 - float64(res)/100)))
+	}
+
+	estimate := &FightEstimate{
+		CharacterTurns: turnsToKill(monster.Hp, characterDamage),
+		MonsterTurns:   turnsToKill(character.MaxHP, monsterDamage),
+	}
+	// turns alternate so the character's nth hit lands on turn 2n-1
+	estimate.Win = estimate.CharacterTurns <= estimate.MonsterTurns && 2*estimate.CharacterTurns-1 <= maxFightTurns
+	return estimate, nil
+}
+
+func turnsToKill(hp, damage int) int {
+	if damage <= 0 {
+		return math.MaxInt32
+	}
+	return (hp + damage - 1) / damage
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+var elements = []string{"fire", "earth", "water", "air"}
+
+// GearTarget is the activity a loadout is chosen for. Set Monster for combat or
+// Skill for gathering; the zero value scores gear on its general combat value.
+type GearTarget struct {
+	Monster string
+	Skill   Skill
+}
+
+// Loadout maps each slot to the code of the item it should hold.
+type Loadout map[Slot]string
+
+type gearCandidate struct {
+	item      CraftableItem
+	available int
+	score     float64
+	equipped  bool
+}
+
+func (c Character) elementStats(element string) (int, int, int) {
+	switch element {
+	case "fire":
+		return c.AttackFire, c.DmgFire, c.ResFire
+	case "earth":
+		return c.AttackEarth, c.DmgEarth, c.ResEarth
+	case "water":
+		return c.AttackWater, c.DmgWater, c.ResWater
+	case "air":
+		return c.AttackAir, c.DmgAir, c.ResAir
+	}
+	return 0, 0, 0
+}
+
+func (m MonsterData) elementStats(element string) (int, int) {
+	switch element {
+	case "fire":
+		return m.AttackFire, m.ResFire
+	case "earth":
+		return m.AttackEarth, m.ResEarth
+	case "water":
+		return m.AttackWater, m.ResWater
+	case "air":
+		return m.AttackAir, m.ResAir
+	}
+	return 0, 0
+}
+
+func (i CraftableItem) effectValues() map[string]int {
+	out := make(map[string]int, len(i.Effects))
+	for _, effect := range i.Effects {
+		out[effect.Name] += effect.Value
+	}
+	return out
+}
+
+// scoreItem estimates how much an item helps the character with target.
+func (c *Svc) scoreItem(character *Character, item CraftableItem, target GearTarget) float64 {
+	effects := item.effectValues()
+
+	if target.Skill != "" && target.Skill != SkillCombat {
+		// gathering effects are cooldown reductions expressed as negative percentages
+		return float64(-effects[string(target.Skill)])
+	}
+
+	score := float64(effects["hp"]) / 4
+	monster, found := c.GetMonster(target.Monster)
+	for _, element := range elements {
+		attack, dmg, _ := character.elementStats(element)
+		if !found {
+			score += float64(effects["attack_"+element] + effects["dmg_"+element] + effects["res_"+element])
+			continue
+		}
+
+		monsterAttack, monsterRes := monster.elementStats(element)
+		hitRate := 1 - float64(monsterRes)/100
+		score += float64(effects["attack_"+element]) * hitRate * (1 + float64(dmg)/100)
+		score += float64(effects["dmg_"+element]) / 100 * float64(attack) * hitRate
+		score += float64(effects["res_"+element]) / 100 * float64(monsterAttack)
+	}
+	return score
+}
+
+// availableGear counts every item the character could equip: inventory, bank and
+// the items already worn.
+func (c *Svc) availableGear(character *Character) map[string]int {
+	out := map[string]int{}
+	for code, quantity := range c.bankSnapshot() {
+		out[code] += quantity
+	}
+	for _, slot := range character.Inventory {
+		if slot.Code != "" {
+			out[slot.Code] += slot.Quantity
+		}
+	}
+	for _, item := range character.EquippedItems() {
+		out[item.Code] += item.Quantity
+	}
+	return out
+}
+
+// BestLoadout computes the best gear for target out of the items in the bank,
+// the inventory and the character's current equipment. Utility slots are left out.
+func (c *Svc) BestLoadout(characterName string, target GearTarget) (Loadout, error) {
+	if target.Monster != "" {
+		if _, found := c.GetMonster(target.Monster); !found {
+			return nil, fmt.Errorf("unknown monster %s", target.Monster)
+		}
+	}
+
+	character := c.GetCharacterByName(characterName)
+	available := c.availableGear(character)
+	loadout := Loadout{}
+
+	for itemType, slots := range slotsByItemType {
+		if itemType == "utility" {
+			continue
+		}
+
+		candidates := []gearCandidate{}
+		for code, quantity := range available {
+			item := c.GetItem(code)
+			if item.Type != itemType || item.Level > character.Level || quantity <= 0 {
+				continue
+			}
+			candidate := gearCandidate{
+				item:      item,
+				available: quantity,
+				score:     c.scoreItem(character, item, target),
+				equipped:  character.IsEquipped(code),
+			}
+			// only swap in new gear that actually helps
+			if candidate.score <= 0 && !candidate.equipped {
+				continue
+			}
+			candidates = append(candidates, candidate)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			if candidates[i].equipped != candidates[j].equipped {
+				return candidates[i].equipped
+			}
+			return candidates[i].item.Code < candidates[j].item.Code
+		})
+
+		chosen := []string{}
+		for _, candidate := range candidates {
+			copies := candidate.available
+			if itemType == "artifact" {
+				// the same artifact cannot be worn twice
+				copies = 1
+			}
+			for n := 0; n < copies && len(chosen) < len(slots); n++ {
+				chosen = append(chosen, candidate.item.Code)
+			}
+		}
+
+		// keep items in the slot they already occupy to avoid needless swaps
+		free := []Slot{}
+		for _, slot := range slots {
+			current := character.EquippedItem(slot).Code
+			if idx := indexOf(chosen, current); current != "" && idx >= 0 {
+				loadout[slot] = current
+				chosen = append(chosen[:idx], chosen[idx+1:]...)
+				continue
+			}
+			free = append(free, slot)
+		}
+		for i, slot := range free {
+			if i < len(chosen) {
+				loadout[slot] = chosen[i]
+			}
+		}
+	}
+
+	return loadout, nil
+}
+
+// ApplyLoadout equips the loadout, withdrawing missing pieces from the bank and
+// depositing the pieces it replaces.
+func (c *Svc) ApplyLoadout(characterName string, loadout Loadout) error {
+	replaced := []string{}
+	for _, slot := range Slots {
+		want, ok := loadout[slot]
+		if !ok {
+			continue
+		}
+		current := c.GetCharacterByName(characterName).EquippedItem(slot)
+		if current.Code == want {
+			continue
+		}
+
+		if current.Code != "" {
+			if err := c.Unequip(characterName, slot, current.Quantity); err != nil {
+				return fmt.Errorf("unequipping %s: %w", slot, err)
+			}
+			replaced = append(replaced, current.Code)
+		}
+		if want == "" {
+			continue
+		}
+
+		// take the piece off a slot the loadout does not want it in
+		for _, other := range Slots {
+			if other != slot && loadout[other] != want && c.GetCharacterByName(characterName).EquippedItem(other).Code == want {
+				if err := c.Unequip(characterName, other, 1); err != nil {
+					return fmt.Errorf("unequipping %s: %w", other, err)
+				}
+				break
+			}
+		}
+		if err := c.fetchItem(characterName, want, 1); err != nil {
+			return fmt.Errorf("fetching %s: %w", want, err)
+		}
+		if err := c.Equip(characterName, c.GetItem(want), slot, 1); err != nil {
+			return fmt.Errorf("equipping %s in %s: %w", want, slot, err)
+		}
+	}
+
+	for _, code := range replaced {
+		found, quantity := c.GetCharacterByName(characterName).FindItemInInventory(code)
+		if !found {
+			// the piece moved to another slot
+			continue
+		}
+		if err := c.DepositBank(characterName, InventorySlot{Code: code, Quantity: quantity}); err != nil {
+			return fmt.Errorf("depositing replaced %s: %w", code, err)
+		}
+		c.GetCharacterByName(characterName).WaitForCooldown()
+	}
+
+	return nil
+}
+
+// OptimizeGear computes and applies the best loadout for target.
+func (c *Svc) OptimizeGear(characterName string, target GearTarget) error {
+	loadout, err := c.BestLoadout(characterName, target)
+	if err != nil {
+		return fmt.Errorf("computing best loadout: %w", err)
+	}
+	fmt.Printf("%s best loadout: %v\n", characterName, loadout)
+
+	if err := c.ApplyLoadout(characterName, loadout); err != nil {
+		return fmt.Errorf("applying loadout: %w", err)
+	}
+	return nil
+}
+
+// fetchItem makes sure quantity of code is in the inventory, withdrawing the
+// difference from the bank.
+func (c *Svc) fetchItem(characterName, code string, quantity int) error {
+	_, inInventory := c.GetCharacterByName(characterName).FindItemInInventory(code)
+	if inInventory >= quantity {
+		return nil
+	}
+
+	withdrawn, err := c.WithdrawFromBankIfFound(characterName, code, quantity-inInventory)
+	if err != nil {
+		return fmt.Errorf("withdrawing %s: %w", code, err)
+	}
+	c.GetCharacterByName(characterName).WaitForCooldown()
+	if inInventory+withdrawn < quantity {
+		return fmt.Errorf("only %d of %d %s available", inInventory+withdrawn, quantity, code)
+	}
+	return nil
+}
+
+func indexOf(codes []string, code string) int {
+	for i, c := range codes {
+		if c == code {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,420 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	GrandExchangeCode = "grand_exchange"
+
+	OrderTypeSell = "sell"
+	OrderTypeBuy  = "buy"
+)
+
+type GEOrder struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Account   string `json:"account"`
+	Code      string `json:"code"`
+	Quantity  int    `json:"quantity"`
+	Price     int    `json:"price"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GEOrdersResponse struct {
+	Data  []GEOrder    `json:"data"`
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+	Size  int          `json:"size"`
+	Pages int          `json:"pages"`
+	Error ErrorMessage `json:"error"`
+}
+
+type GETrade struct {
+	OrderID  string `json:"order_id"`
+	Seller   string `json:"seller"`
+	Buyer    string `json:"buyer"`
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+	SoldAt   string `json:"sold_at"`
+}
+
+type GEHistoryResponse struct {
+	Data  []GETrade    `json:"data"`
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+	Size  int          `json:"size"`
+	Pages int          `json:"pages"`
+	Error ErrorMessage `json:"error"`
+}
+
+type GEOrderBody struct {
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+// GEBuyBody fills quantity of the sell order with ID. The Grand Exchange has no
+// priced buy orders, buying always takes from an existing listing.
+type GEBuyBody struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
+}
+
+type GETransactionResponse struct {
+	Data  GETransactionData `json:"data"`
+	Error ErrorMessage      `json:"error"`
+}
+
+type GETransactionData struct {
+	Cooldown  Cooldown      `json:"cooldown"`
+	Order     GETransaction `json:"order"`
+	Character Character     `json:"character"`
+}
+
+type GETransaction struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Quantity   int    `json:"quantity"`
+	Price      int    `json:"price"`
+	TotalPrice int    `json:"total_price"`
+}
+
+// geFill is the part of a purchase taken from one sell order.
+type geFill struct {
+	Order    GEOrder
+	Quantity int
+}
+
+type GECancelBody struct {
+	ID string `json:"id"`
+}
+
+type GEOrderResponse struct {
+	Data  GEOrderData  `json:"data"`
+	Error ErrorMessage `json:"error"`
+}
+
+type GEOrderData struct {
+	Cooldown  Cooldown  `json:"cooldown"`
+	Order     GEOrder   `json:"order"`
+	Character Character `json:"character"`
+}
+
+// GEPrice summarises the open orders for an item. Zero prices mean no orders of that side.
+type GEPrice struct {
+	Code        string
+	LowestAsk   int
+	AskQuantity int
+	HighestBid  int
+	BidQuantity int
+}
+
+// Spread is the gap between the lowest ask and the highest bid.
+func (p GEPrice) Spread() int {
+	if p.LowestAsk == 0 || p.HighestBid == 0 {
+		return 0
+	}
+	return p.LowestAsk - p.HighestBid
+}
+
+func (c *ArtifactsClient) GetGEOrders(code, orderType string, pageNumber int) (*GEOrdersResponse, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	if code != "" {
+		p["code"] = code
+	}
+	if orderType != "" {
+		p["type"] = orderType
+	}
+	return c.getGEOrders("/grandexchange/orders", p)
+}
+
+func (c *ArtifactsClient) GetMyGEOrders(pageNumber int) (*GEOrdersResponse, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	return c.getGEOrders("/my/grandexchange/orders", p)
+}
+
+func (c *ArtifactsClient) getGEOrders(path string, params map[string]string) (*GEOrdersResponse, error) {
+	respBytes, err := c.Do(http.MethodGet, path, params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing orders request: %w", err)
+	}
+
+	ordersResp := GEOrdersResponse{}
+	if err := json.Unmarshal(respBytes, &ordersResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if ordersResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", ordersResp.Error.Code, ordersResp.Error.Message)
+	}
+	return &ordersResp, nil
+}
+
+func (c *ArtifactsClient) GetGEHistory(pageNumber int) (*GEHistoryResponse, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/my/grandexchange/history", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing history request: %w", err)
+	}
+
+	historyResp := GEHistoryResponse{}
+	if err := json.Unmarshal(respBytes, &historyResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if historyResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", historyResp.Error.Code, historyResp.Error.Message)
+	}
+	return &historyResp, nil
+}
+
+func (c *ArtifactsClient) PlaceGESellOrder(characterName, code string, quantity, price int) (*GEOrderData, error) {
+	path := fmt.Sprintf("/my/%s/action/grandexchange/sell", characterName)
+	body := GEOrderBody{
+		Code:     code,
+		Quantity: quantity,
+		Price:    price,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	return c.geAction(path, bodyBytes)
+}
+
+func (c *ArtifactsClient) BuyGEOrder(characterName, id string, quantity int) (*GETransactionData, error) {
+	path := fmt.Sprintf("/my/%s/action/grandexchange/buy", characterName)
+	bodyBytes, err := json.Marshal(GEBuyBody{ID: id, Quantity: quantity})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	respBytes, err := c.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("executing grand exchange buy request: %w", err)
+	}
+
+	transactionResp := GETransactionResponse{}
+	if err := json.Unmarshal(respBytes, &transactionResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if transactionResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", transactionResp.Error.Code, transactionResp.Error.Message)
+	}
+	return &transactionResp.Data, nil
+}
+
+func (c *ArtifactsClient) CancelGEOrder(characterName, id string) (*GEOrderData, error) {
+	path := fmt.Sprintf("/my/%s/action/grandexchange/cancel", characterName)
+	bodyBytes, err := json.Marshal(GECancelBody{ID: id})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	return c.geAction(path, bodyBytes)
+}
+
+func (c *ArtifactsClient) geAction(path string, bodyBytes []byte) (*GEOrderData, error) {
+	respBytes, err := c.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("executing grand exchange request: %w", err)
+	}
+
+	orderResp := GEOrderResponse{}
+	if err := json.Unmarshal(respBytes, &orderResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if orderResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", orderResp.Error.Code, orderResp.Error.Message)
+	}
+	return &orderResp.Data, nil
+}
+
+// GEOrders lists every open order for code, or for all items when code is empty.
+func (c *Svc) GEOrders(code string) ([]GEOrder, error) {
+	orders := []GEOrder{}
+	for page := 1; ; page++ {
+		resp, err := c.Client.GetGEOrders(code, "", page)
+		if err != nil {
+			return nil, fmt.Errorf("getting orders page %d: %w", page, err)
+		}
+		orders = append(orders, resp.Data...)
+		if len(resp.Data) == 0 || page >= resp.Pages {
+			return orders, nil
+		}
+	}
+}
+
+// GEPrices summarises the open orders of every item listed on the Grand Exchange.
+func (c *Svc) GEPrices() (map[string]GEPrice, error) {
+	orders, err := c.GEOrders("")
+	if err != nil {
+		return nil, fmt.Errorf("listing orders: %w", err)
+	}
+	return summarisePrices(orders), nil
+}
+
+// GetGEPrice returns the lowest ask and highest bid for code.
+func (c *Svc) GetGEPrice(code string) (GEPrice, error) {
+	orders, err := c.GEOrders(code)
+	if err != nil {
+		return GEPrice{}, fmt.Errorf("listing %s orders: %w", code, err)
+	}
+	price, found := summarisePrices(orders)[code]
+	if !found {
+		return GEPrice{Code: code}, nil
+	}
+	return price, nil
+}
+
+func summarisePrices(orders []GEOrder) map[string]GEPrice {
+	prices := make(map[string]GEPrice)
+	for _, order := range orders {
+		price := prices[order.Code]
+		price.Code = order.Code
+		switch {
+		case order.Type == OrderTypeSell && (price.LowestAsk == 0 || order.Price < price.LowestAsk):
+			price.LowestAsk, price.AskQuantity = order.Price, order.Quantity
+		case order.Type == OrderTypeSell && order.Price == price.LowestAsk:
+			price.AskQuantity += order.Quantity
+		case order.Type == OrderTypeBuy && order.Price > price.HighestBid:
+			price.HighestBid, price.BidQuantity = order.Price, order.Quantity
+		case order.Type == OrderTypeBuy && order.Price == price.HighestBid:
+			price.BidQuantity += order.Quantity
+		}
+		prices[order.Code] = price
+	}
+	return prices
+}
+
+// MyGEOrders lists the account's open orders.
+func (c *Svc) MyGEOrders() ([]GEOrder, error) {
+	orders := []GEOrder{}
+	for page := 1; ; page++ {
+		resp, err := c.Client.GetMyGEOrders(page)
+		if err != nil {
+			return nil, fmt.Errorf("getting orders page %d: %w", page, err)
+		}
+		orders = append(orders, resp.Data...)
+		if len(resp.Data) == 0 || page >= resp.Pages {
+			return orders, nil
+		}
+	}
+}
+
+// GEHistory lists the account's completed trades.
+func (c *Svc) GEHistory() ([]GETrade, error) {
+	trades := []GETrade{}
+	for page := 1; ; page++ {
+		resp, err := c.Client.GetGEHistory(page)
+		if err != nil {
+			return nil, fmt.Errorf("getting history page %d: %w", page, err)
+		}
+		trades = append(trades, resp.Data...)
+		if len(resp.Data) == 0 || page >= resp.Pages {
+			return trades, nil
+		}
+	}
+}
+
+// SellOnGE moves the character to the nearest Grand Exchange and lists quantity of
+// code at price each.
+func (c *Svc) SellOnGE(characterName, code string, quantity, price int) (*GEOrder, error) {
+	if err := c.moveToContent(characterName, GrandExchangeCode); err != nil {
+		return nil, fmt.Errorf("moving to grand exchange: %w", err)
+	}
+
+	fmt.Printf("%s listing %d %s at %d gold\n", characterName, quantity, code, price)
+	orderData, err := c.Client.PlaceGESellOrder(characterName, code, quantity, price)
+	if err != nil {
+		return nil, fmt.Errorf("placing sell order: %w", err)
+	}
+	c.setCharacter(characterName, &orderData.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return &orderData.Order, nil
+}
+
+// BuyOnGE moves the character to the nearest Grand Exchange and buys quantity of the
+// items listed by the sell order orderID.
+func (c *Svc) BuyOnGE(characterName, orderID string, quantity int) (*GETransaction, error) {
+	if err := c.moveToContent(characterName, GrandExchangeCode); err != nil {
+		return nil, fmt.Errorf("moving to grand exchange: %w", err)
+	}
+
+	fmt.Printf("%s buying %d from order %s\n", characterName, quantity, orderID)
+	transactionData, err := c.Client.BuyGEOrder(characterName, orderID, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("buying from order %s: %w", orderID, err)
+	}
+	c.setCharacter(characterName, &transactionData.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return &transactionData.Order, nil
+}
+
+// planGEBuy picks the cheapest sell orders that together hold quantity of code and
+// returns them with their total cost. It returns nothing when not enough is listed.
+func (c *Svc) planGEBuy(code string, quantity int) ([]geFill, int, error) {
+	orders, err := c.GEOrders(code)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing %s orders: %w", code, err)
+	}
+	asks := []GEOrder{}
+	for _, order := range orders {
+		if order.Type == OrderTypeSell && order.Code == code && order.Quantity > 0 {
+			asks = append(asks, order)
+		}
+	}
+	sort.Slice(asks, func(i, j int) bool {
+		return asks[i].Price < asks[j].Price
+	})
+
+	fills := []geFill{}
+	cost, remaining := 0, quantity
+	for _, ask := range asks {
+		if remaining <= 0 {
+			break
+		}
+		take := ask.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		fills = append(fills, geFill{Order: ask, Quantity: take})
+		cost += take * ask.Price
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, 0, nil
+	}
+	return fills, cost, nil
+}
+
+// CancelGEOrder moves the character to the nearest Grand Exchange and cancels the
+// order, returning its items or gold to the character.
+func (c *Svc) CancelGEOrder(characterName, id string) error {
+	if err := c.moveToContent(characterName, GrandExchangeCode); err != nil {
+		return fmt.Errorf("moving to grand exchange: %w", err)
+	}
+
+	fmt.Printf("%s cancelling order %s\n", characterName, id)
+	orderData, err := c.Client.CancelGEOrder(characterName, id)
+	if err != nil {
+		return fmt.Errorf("cancelling order: %w", err)
+	}
+	c.setCharacter(characterName, &orderData.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const defaultHealThreshold = 25.0
+
+type UseItemResponse struct {
+	Data  UseItemData  `json:"data"`
+	Error ErrorMessage `json:"error"`
+}
+
+type UseItemData struct {
+	Cooldown  Cooldown      `json:"cooldown"`
+	Item      CraftableItem `json:"item"`
+	Character Character     `json:"character"`
+}
+
+// HealPolicy controls when and how a character recovers hp between fights.
+type HealPolicy struct {
+	// HPThreshold is the hp percentage below which the character heals. Defaults to 25.
+	HPThreshold float64
+	// UseFood eats food from the inventory before falling back to resting.
+	UseFood bool
+	// FromBank withdraws food from the bank when the inventory has none.
+	FromBank bool
+}
+
+type Healing struct {
+	mu       sync.Mutex
+	Policies map[string]HealPolicy
+}
+
+func NewHealing() Healing {
+	return Healing{
+		mu:       sync.Mutex{},
+		Policies: make(map[string]HealPolicy),
+	}
+}
+
+func (c *Svc) SetHealPolicy(characterName string, policy HealPolicy) {
+	c.Healing.mu.Lock()
+	defer c.Healing.mu.Unlock()
+	c.Healing.Policies[characterName] = policy
+}
+
+func (c *Svc) healPolicy(characterName string) HealPolicy {
+	c.Healing.mu.Lock()
+	defer c.Healing.mu.Unlock()
+
+	policy := c.Healing.Policies[characterName]
+	if policy.HPThreshold <= 0 {
+		policy.HPThreshold = defaultHealThreshold
+	}
+	return policy
+}
+
+func (c Character) HPPercent() float64 {
+	if c.MaxHP == 0 {
+		return 0
+	}
+	return float64(c.Hp) / float64(c.MaxHP) * 100.0
+}
+
+func (c *Svc) needsHealing(characterName string) bool {
+	return c.GetCharacterByName(characterName).HPPercent() < c.healPolicy(characterName).HPThreshold
+}
+
+func healValue(item CraftableItem) int {
+	return item.effectValues()["heal"]
+}
+
+// Use consumes quantity of an item from the character's inventory.
+func (c *Svc) Use(characterName, code string, quantity int) error {
+	fmt.Printf("%s using %d %s\n", characterName, quantity, code)
+	path := fmt.Sprintf("/my/%s/action/use", characterName)
+	body := SimpleItem{
+		Code:     code,
+		Quantity: quantity,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshalling body: %w", err)
+	}
+	respBytes, err := c.Client.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return fmt.Errorf("executing use request: %w", err)
+	}
+
+	useResp := UseItemResponse{}
+	if err := json.Unmarshal(respBytes, &useResp); err != nil {
+		return fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+
+	if useResp.Error.Code != 0 {
+		return fmt.Errorf("error response received: status code: %d, error message: %s", useResp.Error.Code, useResp.Error.Message)
+	}
+
+	c.setCharacter(characterName, &useResp.Data.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return nil
+}
+
+// Heal restores the character's hp, eating the food that best matches the missing hp
+// when the policy allows it and resting otherwise.
+func (c *Svc) Heal(characterName string) error {
+	character := c.GetCharacterByName(characterName)
+	fmt.Printf("%s HP below threshold: %.2f, HP: %d MaxHP: %d\n", characterName, character.HPPercent(), character.Hp, character.MaxHP)
+
+	policy := c.healPolicy(characterName)
+	if policy.UseFood {
+		if err := c.eat(characterName, policy); err != nil {
+			return fmt.Errorf("eating: %w", err)
+		}
+		if !c.needsHealing(characterName) {
+			return nil
+		}
+	}
+
+	if err := c.Rest(characterName); err != nil {
+		return fmt.Errorf("executing rest request: %w", err)
+	}
+	return nil
+}
+
+func (c *Svc) eat(characterName string, policy HealPolicy) error {
+	code, heal := c.bestFood(characterName, false)
+	if code == "" && policy.FromBank {
+		code, heal = c.bestFood(characterName, true)
+		if code != "" {
+			character := c.GetCharacterByName(characterName)
+			position := Coordinates{character.X, character.Y}
+			if _, err := c.WithdrawFromBankIfFound(characterName, code, foodQuantity(character, heal)); err != nil {
+				return fmt.Errorf("withdrawing %s: %w", code, err)
+			}
+			c.GetCharacterByName(characterName).WaitForCooldown()
+			if _, err := c.MoveCharacter(characterName, position.X, position.Y); err != nil {
+				return fmt.Errorf("moving back from bank: %w", err)
+			}
+		}
+	}
+	if code == "" {
+		fmt.Printf("%s has no food available\n", characterName)
+		return nil
+	}
+
+	character := c.GetCharacterByName(characterName)
+	quantity := foodQuantity(character, heal)
+	if _, inInventory := character.FindItemInInventory(code); inInventory < quantity {
+		quantity = inInventory
+	}
+	if quantity <= 0 {
+		return nil
+	}
+	return c.Use(characterName, code, quantity)
+}
+
+// bestFood picks the food whose heal best matches the character's missing hp:
+// the largest heal that doesn't overshoot, or the smallest one if they all do.
+func (c *Svc) bestFood(characterName string, fromBank bool) (string, int) {
+	character := c.GetCharacterByName(characterName)
+	missing := character.MaxHP - character.Hp
+
+	available := map[string]int{}
+	if fromBank {
+		available = c.bankSnapshot()
+	} else {
+		for _, slot := range character.Inventory {
+			if slot.Code != "" {
+				available[slot.Code] += slot.Quantity
+			}
+		}
+	}
+
+	bestCode, bestHeal := "", 0
+	for code, quantity := range available {
+		item := c.GetItem(code)
+		heal := healValue(item)
+		if quantity <= 0 || heal <= 0 || item.Level > character.Level {
+			continue
+		}
+
+		switch {
+		case bestCode == "":
+		case bestHeal > missing && heal < bestHeal:
+		case heal <= missing && heal > bestHeal:
+		default:
+			continue
+		}
+		bestCode, bestHeal = code, heal
+	}
+	return bestCode, bestHeal
+}
+
+// foodQuantity returns how many items healing heal each it takes to refill the character.
+func foodQuantity(character *Character, heal int) int {
+	quantity := (character.MaxHP - character.Hp) / heal
+	if quantity < 1 {
+		quantity = 1
+	}
+	return quantity
+}
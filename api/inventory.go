@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+)
+
+const defaultInventoryBuffer = 5
+
+// InventoryPolicy controls what a character keeps on hand and when it banks the rest.
+type InventoryPolicy struct {
+	// Keep lists item codes that are never deposited.
+	Keep []string
+	// MinQuantities keeps at least this many of an item when depositing.
+	MinQuantities map[string]int
+	KeepFood      bool
+	KeepTools     bool
+	KeepTaskItems bool
+
+	// ItemBuffer triggers a bank trip once fewer than this many items fit. Defaults to 5.
+	ItemBuffer int
+	// MaxUsedSlots triggers a bank trip once this many inventory slots are used.
+	// Zero disables the check.
+	MaxUsedSlots int
+
+	// DepositGold banks any gold above KeepGold on each deposit.
+	DepositGold bool
+	KeepGold    int
+}
+
+type Inventories struct {
+	mu       sync.Mutex
+	Policies map[string]InventoryPolicy
+}
+
+func NewInventories() Inventories {
+	return Inventories{
+		mu:       sync.Mutex{},
+		Policies: make(map[string]InventoryPolicy),
+	}
+}
+
+func (c *Svc) SetInventoryPolicy(characterName string, policy InventoryPolicy) {
+	c.Inventories.mu.Lock()
+	defer c.Inventories.mu.Unlock()
+	c.Inventories.Policies[characterName] = policy
+}
+
+func (c *Svc) inventoryPolicy(characterName string) InventoryPolicy {
+	c.Inventories.mu.Lock()
+	defer c.Inventories.mu.Unlock()
+
+	policy := c.Inventories.Policies[characterName]
+	if policy.ItemBuffer <= 0 {
+		policy.ItemBuffer = defaultInventoryBuffer
+	}
+	return policy
+}
+
+// keepQuantity returns how many of code the character holds on to when depositing.
+// A negative value means all of it.
+func (c *Svc) keepQuantity(character *Character, policy InventoryPolicy, code string) int {
+	item := c.GetItem(code)
+	switch {
+	case indexOf(policy.Keep, code) >= 0:
+		return -1
+	case policy.KeepFood && healValue(item) > 0:
+		return -1
+	case policy.KeepTools && item.Subtype == "tool":
+		return -1
+	case policy.KeepTaskItems && character.TaskType == "items" && character.Task == code:
+		return -1
+	}
+	return policy.MinQuantities[code]
+}
+
+// NeedsBankTrip reports whether the character's inventory policy calls for a deposit.
+func (c *Svc) NeedsBankTrip(characterName string) bool {
+	character := c.GetCharacterByName(characterName)
+	policy := c.inventoryPolicy(characterName)
+
+	full := character.FreeItemSpace() < policy.ItemBuffer || character.FreeSlots() == 0 ||
+		policy.MaxUsedSlots > 0 && character.UsedSlots() >= policy.MaxUsedSlots
+	// a trip that would deposit nothing frees no room, however full the inventory is
+	return full && len(c.depositPlan(characterName)) > 0
+}
+
+func (c Character) InventoryCount() int {
+	total := 0
+	for _, slot := range c.Inventory {
+		total += slot.Quantity
+	}
+	return total
+}
+
+func (c Character) UsedSlots() int {
+	used := 0
+	for _, slot := range c.Inventory {
+		if slot.Code != "" {
+			used++
+		}
+	}
+	return used
+}
+
+// FreeItemSpace returns how many more items fit in the inventory.
+func (c Character) FreeItemSpace() int {
+	return c.InventoryMaxItems - c.InventoryCount()
+}
+
+// FreeSlots returns how many more distinct items fit in the inventory.
+func (c Character) FreeSlots() int {
+	return len(c.Inventory) - c.UsedSlots()
+}
+
+// CanFit reports whether the worst case of an action's drop table fits in the
+// inventory, both by item count and by distinct slots.
+func (c Character) CanFit(drops []Drop) bool {
+	items, newSlots := 0, 0
+	for _, drop := range drops {
+		items += drop.MaxQuantity
+		if found, _ := c.FindItemInInventory(drop.Code); !found {
+			newSlots++
+		}
+	}
+	return items <= c.FreeItemSpace() && newSlots <= c.FreeSlots()
+}
+
+// makeRoomFor banks the inventory when the policy asks for it or when an action that
+// may drop any of drops wouldn't fit, and reports whether it did. It fails when the
+// items kept by the policy leave no room even after depositing.
+func (c *Svc) makeRoomFor(characterName string, drops []Drop) (bool, error) {
+	if !c.NeedsBankTrip(characterName) && c.GetCharacterByName(characterName).CanFit(drops) {
+		return false, nil
+	}
+	if len(c.depositPlan(characterName)) == 0 {
+		return false, fmt.Errorf("no room for drops and the inventory policy keeps every item held")
+	}
+	if err := c.DepositAllItems(characterName); err != nil {
+		return false, fmt.Errorf("depositing inventory: %w", err)
+	}
+	if !c.GetCharacterByName(characterName).CanFit(drops) {
+		return true, fmt.Errorf("no room for drops after depositing, kept items fill the inventory")
+	}
+	return true, nil
+}
+
+// depositPlan returns what DepositAllItems would bank under the character's policy.
+func (c *Svc) depositPlan(characterName string) []InventorySlot {
+	character := c.GetCharacterByName(characterName)
+	policy := c.inventoryPolicy(characterName)
+
+	out := []InventorySlot{}
+	for _, slot := range character.Inventory {
+		if slot.Code == "" {
+			continue
+		}
+		keep := c.keepQuantity(character, policy, slot.Code)
+		if keep < 0 || slot.Quantity <= keep {
+			continue
+		}
+		out = append(out, InventorySlot{
+			Slot:     slot.Slot,
+			Code:     slot.Code,
+			Quantity: slot.Quantity - keep,
+		})
+	}
+	return out
+}
+
+func (c *Svc) depositGoldByPolicy(characterName string) error {
+	policy := c.inventoryPolicy(characterName)
+	if !policy.DepositGold {
+		return nil
+	}
+	amount := c.GetCharacterByName(characterName).Gold - policy.KeepGold
+	if amount <= 0 {
+		return nil
+	}
+	if err := c.DepositGold(characterName, amount); err != nil {
+		return fmt.Errorf("depositing %d gold: %w", amount, err)
+	}
+	c.GetCharacterByName(characterName).WaitForCooldown()
+	return nil
+}
@@ -38,7 +38,7 @@ type Effect struct {
 }
 
 type Craft struct {
-	Skill    string       `json:"skill"`
+	Skill    Skill        `json:"skill"`
 	Level    int          `json:"level"`
 	Items    []SimpleItem `json:"items"`
 	Quantity int          `json:"quantity"`
@@ -0,0 +1,315 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	ActivityGather = "gather"
+	ActivityCraft  = "craft"
+
+	// estimates used until an activity has been observed
+	defaultGatherSeconds = 25.0
+	defaultCraftSeconds  = 10.0
+	defaultFightSeconds  = 30.0
+
+	// skills stop granting xp for content this many levels below the character
+	xpLevelRange = 10
+
+	gatherBatchSize  = 20
+	maxCraftBatch    = 10
+	maxPlanningDepth = 5
+)
+
+// Activity is a single way of earning xp in a skill.
+type Activity struct {
+	Type string
+	Code string
+	// Resource is the resource gathered for a gather activity.
+	Resource    string
+	Level       int
+	XpPerMinute float64
+}
+
+// ActivityStats records the xp and cooldown observed per gathered or crafted item.
+type ActivityStats struct {
+	mu     sync.Mutex
+	ByCode map[string]ActivityStat
+}
+
+type ActivityStat struct {
+	Actions int
+	Xp      int
+	Seconds int
+}
+
+func NewActivityStats() ActivityStats {
+	return ActivityStats{
+		mu:     sync.Mutex{},
+		ByCode: make(map[string]ActivityStat),
+	}
+}
+
+func (c *Svc) recordActivity(code string, actions int, details SkillDetails, cooldown Cooldown) {
+	c.Activities.mu.Lock()
+	defer c.Activities.mu.Unlock()
+
+	stat := c.Activities.ByCode[code]
+	stat.Actions += actions
+	stat.Xp += details.Xp
+	stat.Seconds += cooldown.TotalSeconds
+	c.Activities.ByCode[code] = stat
+}
+
+// activityEstimate returns the expected xp and cooldown seconds of a single action
+// producing code, falling back to level based estimates for unseen activities.
+func (c *Svc) activityEstimate(code string, level int, defaultSeconds float64) (float64, float64) {
+	c.Activities.mu.Lock()
+	stat, found := c.Activities.ByCode[code]
+	c.Activities.mu.Unlock()
+
+	if found && stat.Actions > 0 {
+		return float64(stat.Xp) / float64(stat.Actions), float64(stat.Seconds) / float64(stat.Actions)
+	}
+	return float64(level+1) * 5, defaultSeconds
+}
+
+// LevelSkill trains a gathering or crafting skill until targetLevel is reached.
+// The most xp efficient activity is chosen again after every batch so that level-ups
+// and bank stock changes are taken into account.
+func (c *Svc) LevelSkill(characterName string, skill Skill, targetLevel int) error {
+	done, err := c.startLoop(characterName)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return c.withLoadout(characterName, string(skill), func() error {
+		return c.levelSkill(characterName, skill, targetLevel)
+	})
+}
+
+func (c *Svc) levelSkill(characterName string, skill Skill, targetLevel int) error {
+	for {
+		level := c.GetCharacterByName(characterName).SkillLevel(skill)
+		if level >= targetLevel {
+			fmt.Printf("%s reached %s level %d\n", characterName, skill, level)
+			return nil
+		}
+
+		activity, err := c.BestActivity(characterName, skill)
+		if err != nil {
+			return fmt.Errorf("planning %s activity: %w", skill, err)
+		}
+		fmt.Printf("%s leveling %s (%d/%d) by %s %s: %.1f xp/min\n", characterName, skill, level, targetLevel, activity.Type, activity.Code, activity.XpPerMinute)
+
+		switch activity.Type {
+		case ActivityGather:
+			if err := c.gatherActivity(characterName, *activity); err != nil {
+				return fmt.Errorf("gathering %s: %w", activity.Code, err)
+			}
+		case ActivityCraft:
+			if _, err := c.CraftItem(characterName, activity.Code, c.craftBatchSize(characterName, activity.Code)); err != nil {
+				return fmt.Errorf("crafting %s: %w", activity.Code, err)
+			}
+		}
+
+		if err := c.DepositAllItems(characterName); err != nil {
+			return fmt.Errorf("depositing products: %w", err)
+		}
+	}
+}
+
+// gatherActivity gathers a batch at the resource the activity was scored for.
+func (c *Svc) gatherActivity(characterName string, activity Activity) error {
+	for _, resource := range c.GetResourceByCode(activity.Code) {
+		if resource.Code != activity.Resource {
+			continue
+		}
+		coords, _, found := c.nearestCoordinates(characterName, resource.Code)
+		if !found {
+			return fmt.Errorf("no map tile found for %s", resource.Code)
+		}
+		return c.gatherAt(characterName, c.GetItem(activity.Code), resource, coords, gatherBatchSize)
+	}
+	return fmt.Errorf("unknown resource %s", activity.Resource)
+}
+
+// BestActivity returns the gather or craft activity with the highest expected
+// xp per minute for the character's current level in skill.
+func (c *Svc) BestActivity(characterName string, skill Skill) (*Activity, error) {
+	character := c.GetCharacterByName(characterName)
+	level := character.SkillLevel(skill)
+	bank := c.bankSnapshot()
+
+	var best *Activity
+	consider := func(activity Activity) {
+		if activity.XpPerMinute <= 0 || math.IsInf(activity.XpPerMinute, 0) {
+			return
+		}
+		if best == nil || activity.XpPerMinute > best.XpPerMinute {
+			best = &activity
+		}
+	}
+
+	// gathering candidates
+	seen := map[string]bool{}
+	for _, resources := range c.ResourcesByDropCode {
+		for _, resource := range resources {
+			if seen[resource.Code] || resource.Skill != skill || resource.Level > level {
+				continue
+			}
+			seen[resource.Code] = true
+			if level-resource.Level > xpLevelRange {
+				continue
+			}
+			if len(c.GetCoordinatesByCode(resource.Code)) == 0 {
+				continue
+			}
+
+			xp, seconds := c.activityEstimate(mainDrop(resource), resource.Level, defaultGatherSeconds)
+			consider(Activity{
+				Type:        ActivityGather,
+				Code:        mainDrop(resource),
+				Resource:    resource.Code,
+				Level:       resource.Level,
+				XpPerMinute: xp / seconds * 60,
+			})
+		}
+	}
+
+	// crafting candidates
+	for _, item := range c.Items {
+		if item.Craft == nil || item.Craft.Skill != skill || item.Craft.Level > level {
+			continue
+		}
+		if level-item.Craft.Level > xpLevelRange {
+			continue
+		}
+
+		// every candidate is costed against the full bank
+		candidateBank := make(map[string]int, len(bank))
+		for code, quantity := range bank {
+			candidateBank[code] = quantity
+		}
+		xp, seconds := c.activityEstimate(item.Code, item.Craft.Level, defaultCraftSeconds)
+		for _, subItem := range item.Craft.Items {
+			seconds += c.obtainSeconds(character, subItem.Code, subItem.Quantity, candidateBank, 0)
+		}
+		consider(Activity{
+			Type:        ActivityCraft,
+			Code:        item.Code,
+			Level:       item.Craft.Level,
+			XpPerMinute: xp / seconds * 60,
+		})
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no %s activity available at level %d", skill, level)
+	}
+	return best, nil
+}
+
+// obtainSeconds estimates how long it takes to get quantity of code into the
+// inventory. Bank stock is consumed from the snapshot as it is allocated and monsters
+// the fight estimator predicts a loss against are ignored.
+func (c *Svc) obtainSeconds(character *Character, code string, quantity int, bank map[string]int, depth int) float64 {
+	if depth > maxPlanningDepth {
+		return math.Inf(1)
+	}
+
+	fromBank := bank[code]
+	if fromBank > quantity {
+		fromBank = quantity
+	}
+	bank[code] -= fromBank
+	remaining := quantity - fromBank
+	if remaining <= 0 {
+		return 0
+	}
+
+	item := c.GetItem(code)
+	if item.Craft != nil {
+		if !character.AbleToCraft(item.Craft.Skill, item.Craft.Level) {
+			return math.Inf(1)
+		}
+		_, seconds := c.activityEstimate(code, item.Craft.Level, defaultCraftSeconds)
+		for _, subItem := range item.Craft.Items {
+			seconds += c.obtainSeconds(character, subItem.Code, subItem.Quantity, bank, depth+1)
+		}
+		return seconds * float64(remaining)
+	}
+
+	if resources := c.GetResourceByCode(code); len(resources) > 0 {
+		bestSeconds := math.Inf(1)
+		for _, resource := range resources {
+			yield := resource.ExpectedYield(code)
+			if yield <= 0 || !character.AbleToCraft(resource.Skill, resource.Level) || len(c.GetCoordinatesByCode(resource.Code)) == 0 {
+				continue
+			}
+			_, seconds := c.activityEstimate(code, resource.Level, defaultGatherSeconds)
+			if perUnit := seconds / yield; perUnit < bestSeconds {
+				bestSeconds = perUnit
+			}
+		}
+		return bestSeconds * float64(remaining)
+	}
+
+	bestSeconds := math.Inf(1)
+	for _, monster := range c.GetMonsterByDrop(code) {
+		if len(c.GetCoordinatesByCode(monster.Code)) == 0 {
+			continue
+		}
+		if estimate, err := c.EstimateFight(character.Name, monster.Code); err != nil || !estimate.Win {
+			continue
+		}
+		for _, drop := range monster.Drops {
+			if drop.Code != code || drop.Rate <= 0 {
+				continue
+			}
+			perUnit := defaultFightSeconds * float64(drop.Rate) * 2 / float64(drop.MinQuantity+drop.MaxQuantity)
+			if perUnit < bestSeconds {
+				bestSeconds = perUnit
+			}
+		}
+	}
+	return bestSeconds * float64(remaining)
+}
+
+// craftBatchSize returns how many of code fit in an emptied inventory in one go.
+func (c *Svc) craftBatchSize(characterName, code string) int {
+	item := c.GetItem(code)
+	perUnit := 0
+	if item.Craft != nil {
+		for _, subItem := range item.Craft.Items {
+			perUnit += subItem.Quantity
+		}
+	}
+	if perUnit == 0 {
+		return 1
+	}
+
+	batch := (c.GetCharacterByName(characterName).InventoryMaxItems - 10) / perUnit
+	if batch > maxCraftBatch {
+		batch = maxCraftBatch
+	}
+	if batch < 1 {
+		batch = 1
+	}
+	return batch
+}
+
+// mainDrop returns the drop of a resource with the highest expected yield.
+func mainDrop(resource ResourceData) string {
+	code := ""
+	bestYield := 0.0
+	for _, drop := range resource.Drops {
+		if yield := resource.ExpectedYield(drop.Code); yield > bestYield {
+			code = drop.Code
+			bestYield = yield
+		}
+	}
+	return code
+}
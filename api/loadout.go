@@ -0,0 +1,132 @@
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	LoadoutCombat = "combat"
+
+	loadoutsFile = "loadouts.json"
+)
+
+// Loadouts holds the named loadouts of every character and which one each
+// character is currently wearing.
+type Loadouts struct {
+	mu          sync.Mutex
+	ByCharacter map[string]map[string]Loadout
+	active      map[string]string
+}
+
+func NewLoadouts() Loadouts {
+	return Loadouts{
+		mu:          sync.Mutex{},
+		ByCharacter: make(map[string]map[string]Loadout),
+		active:      make(map[string]string),
+	}
+}
+
+func (c *Svc) GetLoadout(characterName, name string) (Loadout, bool) {
+	c.Loadouts.mu.Lock()
+	defer c.Loadouts.mu.Unlock()
+
+	loadout, ok := c.Loadouts.ByCharacter[characterName][name]
+	return loadout, ok
+}
+
+// SaveLoadout stores a named loadout for the character and persists all loadouts.
+func (c *Svc) SaveLoadout(characterName, name string, loadout Loadout) error {
+	c.Loadouts.mu.Lock()
+	defer c.Loadouts.mu.Unlock()
+
+	if c.Loadouts.ByCharacter[characterName] == nil {
+		c.Loadouts.ByCharacter[characterName] = make(map[string]Loadout)
+	}
+	c.Loadouts.ByCharacter[characterName][name] = loadout
+
+	if err := writeJSONFile(filepath.Join(c.DataDir, loadoutsFile), c.Loadouts.ByCharacter); err != nil {
+		return fmt.Errorf("saving loadouts: %w", err)
+	}
+	return nil
+}
+
+// SwitchLoadout equips the named loadout and returns the gear it replaced so it can
+// be restored. Nothing is returned when the loadout is unknown or already worn.
+func (c *Svc) SwitchLoadout(characterName, name string) (Loadout, error) {
+	c.Loadouts.mu.Lock()
+	active := c.Loadouts.active[characterName]
+	c.Loadouts.mu.Unlock()
+	if active == name {
+		return nil, nil
+	}
+
+	loadout, found := c.GetLoadout(characterName, name)
+	if !found {
+		return nil, nil
+	}
+
+	fmt.Printf("%s switching to %s loadout\n", characterName, name)
+	character := c.GetCharacterByName(characterName)
+	previous := Loadout{}
+	for slot := range loadout {
+		previous[slot] = character.EquippedItem(slot).Code
+	}
+
+	if err := c.ApplyLoadout(characterName, loadout); err != nil {
+		return nil, fmt.Errorf("applying %s loadout: %w", name, err)
+	}
+
+	c.Loadouts.mu.Lock()
+	c.Loadouts.active[characterName] = name
+	c.Loadouts.mu.Unlock()
+
+	return previous, nil
+}
+
+// withLoadout runs fn wearing the character's loadout for activity, then puts the
+// previous gear back.
+func (c *Svc) withLoadout(characterName, activity string, fn func() error) error {
+	c.Loadouts.mu.Lock()
+	previousActive := c.Loadouts.active[characterName]
+	c.Loadouts.mu.Unlock()
+
+	previous, err := c.SwitchLoadout(characterName, activity)
+	if err != nil {
+		return fmt.Errorf("switching loadout: %w", err)
+	}
+
+	fnErr := fn()
+	if previous == nil {
+		return fnErr
+	}
+
+	fmt.Printf("%s restoring gear after %s\n", characterName, activity)
+	if err := c.ApplyLoadout(characterName, previous); err != nil {
+		// keep the active loadout as is, the gear worn is still the switched one
+		if fnErr != nil {
+			// go 1.18 wraps a single error, so keep fn's and describe the restore failure
+			return fmt.Errorf("%w (restoring loadout also failed: %v)", fnErr, err)
+		}
+		return fmt.Errorf("restoring loadout: %w", err)
+	}
+
+	c.Loadouts.mu.Lock()
+	c.Loadouts.active[characterName] = previousActive
+	c.Loadouts.mu.Unlock()
+
+	return fnErr
+}
+
+func (c *Svc) populateLoadouts() error {
+	if err := readJSONFile(filepath.Join(c.DataDir, loadoutsFile), &c.Loadouts.ByCharacter); err != nil {
+		return fmt.Errorf("reading loadouts: %w", err)
+	}
+	// a file holding null leaves no map to save into
+	if c.Loadouts.ByCharacter == nil {
+		c.Loadouts.ByCharacter = make(map[string]map[string]Loadout)
+	}
+	fmt.Println("Loadouts successfully populated")
+	return nil
+}
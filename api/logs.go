@@ -0,0 +1,275 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const logsFile = "logs.jsonl"
+
+// Log types of the actions the bot performs.
+const (
+	LogTypeFight        = "fight"
+	LogTypeGathering    = "gathering"
+	LogTypeCrafting     = "crafting"
+	LogTypeDeposit      = "deposit"
+	LogTypeWithdraw     = "withdraw"
+	LogTypeDepositGold  = "deposit_gold"
+	LogTypeWithdrawGold = "withdraw_gold"
+	LogTypeGEBuy        = "buy_ge"
+	LogTypeGESell       = "sell_ge"
+)
+
+type LogsResponse struct {
+	Data  []LogEntry   `json:"data"`
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+	Size  int          `json:"size"`
+	Pages int          `json:"pages"`
+	Error ErrorMessage `json:"error"`
+}
+
+// LogEntry is one action recorded by the server. Content holds the action's details,
+// which differ by Type. Entries returned by the service also have the typed field
+// matching their Type set; other types only keep the raw Content.
+type LogEntry struct {
+	Character          string          `json:"character"`
+	Account            string          `json:"account"`
+	Type               string          `json:"type"`
+	Description        string          `json:"description"`
+	Content            json.RawMessage `json:"content"`
+	Cooldown           int             `json:"cooldown"`
+	CooldownExpiration time.Time       `json:"cooldown_expiration"`
+	CreatedAt          time.Time       `json:"created_at"`
+
+	Fight *FightLog `json:"-"`
+	Skill *SkillLog `json:"-"`
+	Bank  *BankLog  `json:"-"`
+	GE    *GELog    `json:"-"`
+}
+
+// FightLog is the content of a fight entry.
+type FightLog struct {
+	Fight struct {
+		Result string       `json:"result"`
+		Turns  int          `json:"turns"`
+		Xp     int          `json:"xp"`
+		Gold   int          `json:"gold"`
+		Drops  []SimpleItem `json:"drops"`
+	} `json:"fight"`
+}
+
+// SkillLog is the content of a gathering or crafting entry.
+type SkillLog struct {
+	Details SkillDetails `json:"details"`
+}
+
+// BankLog is the content of an item or gold deposit or withdrawal.
+type BankLog struct {
+	Items []SimpleItem `json:"items"`
+	Gold  int          `json:"gold"`
+}
+
+// GELog is the content of a Grand Exchange purchase or listing.
+type GELog struct {
+	Order GETransaction `json:"order"`
+}
+
+// LogFilter selects log entries. Empty fields match every entry.
+type LogFilter struct {
+	Character string
+	Type      string
+	Since     time.Time
+	Until     time.Time
+}
+
+type ActionLogs struct {
+	mu   sync.Mutex
+	seen map[string]bool
+	// started is when this process began recording activity itself
+	started time.Time
+}
+
+func NewActionLogs() ActionLogs {
+	return ActionLogs{
+		mu:      sync.Mutex{},
+		started: time.Now(),
+	}
+}
+
+func (e LogEntry) key() string {
+	return fmt.Sprintf("%s|%s|%s", e.Character, e.Type, e.CreatedAt.Format(time.RFC3339Nano))
+}
+
+// decode sets the typed content field matching the entry's type. Content that doesn't
+// match the expected shape leaves the field unset rather than losing the entry.
+func (e *LogEntry) decode() {
+	var target interface{}
+	switch e.Type {
+	case LogTypeFight:
+		e.Fight = &FightLog{}
+		target = e.Fight
+	case LogTypeGathering, LogTypeCrafting:
+		e.Skill = &SkillLog{}
+		target = e.Skill
+	case LogTypeDeposit, LogTypeWithdraw, LogTypeDepositGold, LogTypeWithdrawGold:
+		e.Bank = &BankLog{}
+		target = e.Bank
+	case LogTypeGEBuy, LogTypeGESell:
+		e.GE = &GELog{}
+		target = e.GE
+	default:
+		return
+	}
+	if err := json.Unmarshal(e.Content, target); err != nil {
+		e.Fight, e.Skill, e.Bank, e.GE = nil, nil, nil, nil
+	}
+}
+
+func (f LogFilter) matches(entry LogEntry) bool {
+	if f.Character != "" && f.Character != entry.Character {
+		return false
+	}
+	if f.Type != "" && f.Type != entry.Type {
+		return false
+	}
+	if !f.Since.IsZero() && entry.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || entry.CreatedAt.Before(f.Until)
+}
+
+func (c *ArtifactsClient) GetLogs(pageNumber int) (*LogsResponse, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/my/logs", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing logs request: %w", err)
+	}
+
+	logsResp := LogsResponse{}
+	if err := json.Unmarshal(respBytes, &logsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if logsResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", logsResp.Error.Code, logsResp.Error.Message)
+	}
+	return &logsResp, nil
+}
+
+// readLogs calls fn with every entry of the local log store.
+func (c *Svc) readLogs(fn func(entry LogEntry)) error {
+	return readJSONLines(filepath.Join(c.DataDir, logsFile), func(line []byte) error {
+		entry := LogEntry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("unmarshalling log entry: %w", err)
+		}
+		entry.decode()
+		fn(entry)
+		return nil
+	})
+}
+
+// SyncLogs fetches the account's server logs and appends those not yet in the local
+// store, returning how many were added. Pages are read newest first until one holds
+// nothing new, so entries from runs on other machines are picked up too. Gathering and
+// crafting from before this process started are merged into the activity stats the
+// planner uses; the task ledger only counts what this bot did and isn't touched.
+func (c *Svc) SyncLogs() (int, error) {
+	c.Logs.mu.Lock()
+	defer c.Logs.mu.Unlock()
+
+	if c.Logs.seen == nil {
+		seen := map[string]bool{}
+		if err := c.readLogs(func(entry LogEntry) { seen[entry.key()] = true }); err != nil {
+			return 0, fmt.Errorf("reading local logs: %w", err)
+		}
+		c.Logs.seen = seen
+	}
+
+	fresh := []LogEntry{}
+	for page := 1; ; page++ {
+		resp, err := c.Client.GetLogs(page)
+		if err != nil {
+			return 0, fmt.Errorf("getting logs page %d: %w", page, err)
+		}
+
+		added := 0
+		for _, entry := range resp.Data {
+			if c.Logs.seen[entry.key()] {
+				continue
+			}
+			entry.decode()
+			c.Logs.seen[entry.key()] = true
+			fresh = append(fresh, entry)
+			added++
+		}
+		if added == 0 || page >= resp.Pages {
+			break
+		}
+	}
+
+	// store oldest first so the file reads chronologically
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].CreatedAt.Before(fresh[j].CreatedAt)
+	})
+	path := filepath.Join(c.DataDir, logsFile)
+	for i, entry := range fresh {
+		if err := appendJSONLine(path, entry); err != nil {
+			// forget what wasn't written so the next sync retries it
+			for _, unsaved := range fresh[i:] {
+				delete(c.Logs.seen, unsaved.key())
+			}
+			return i, fmt.Errorf("saving log entry: %w", err)
+		}
+	}
+
+	for _, entry := range fresh {
+		c.mergeActivity(entry)
+	}
+	fmt.Printf("Synced %d new log entries\n", len(fresh))
+	return len(fresh), nil
+}
+
+// mergeActivity adds a gathering or crafting entry to the activity stats unless this
+// process has recorded it already.
+func (c *Svc) mergeActivity(entry LogEntry) {
+	if entry.Skill == nil || len(entry.Skill.Details.Items) == 0 || !entry.CreatedAt.Before(c.Logs.started) {
+		return
+	}
+	product := entry.Skill.Details.Items[0]
+	actions := 1
+	if entry.Type == LogTypeCrafting && product.Quantity > 0 {
+		actions = product.Quantity
+	}
+	c.recordActivity(product.Code, actions, entry.Skill.Details, Cooldown{TotalSeconds: entry.Cooldown})
+}
+
+// GetLogs returns the locally stored log entries matching filter, oldest first.
+// Call SyncLogs first to include the latest server logs.
+func (c *Svc) GetLogs(filter LogFilter) ([]LogEntry, error) {
+	c.Logs.mu.Lock()
+	defer c.Logs.mu.Unlock()
+
+	entries := []LogEntry{}
+	if err := c.readLogs(func(entry LogEntry) {
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("reading local logs: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
@@ -45,3 +45,76 @@ func (c *ArtifactsClient) GetMaps(pageNumber int) ([]Map, error) {
 	}
 	return mapResp.Data, nil
 }
+
+// Distance returns the number of map tiles between two coordinates.
+func (c Coordinates) Distance(other Coordinates) int {
+	return abs(c.X-other.X) + abs(c.Y-other.Y)
+}
+
+// nearestCoordinates returns the tile holding contentCode closest to the character
+// and how many tiles away it is.
+func (c *Svc) nearestCoordinates(characterName, contentCode string) (Coordinates, int, bool) {
+	character := c.GetCharacterByName(characterName)
+	position := Coordinates{character.X, character.Y}
+
+	var nearest Coordinates
+	minDistance := -1
+	for _, coords := range c.GetCoordinatesByCode(contentCode) {
+		distance := position.Distance(coords)
+		if minDistance < 0 || distance < minDistance {
+			nearest = coords
+			minDistance = distance
+		}
+	}
+
+	return nearest, minDistance, minDistance >= 0
+}
+
+// moveToContent moves the character to the closest tile holding contentCode.
+func (c *Svc) moveToContent(characterName, contentCode string) error {
+	coords, _, found := c.nearestCoordinates(characterName, contentCode)
+	if !found {
+		return fmt.Errorf("no map tile found for %s", contentCode)
+	}
+	if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+		return fmt.Errorf("moving to %s: %w", contentCode, err)
+	}
+	return nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// contentAt returns the code of the content found at coords.
+func (c *Svc) contentAt(coords Coordinates) string {
+	c.mapsMu.RLock()
+	defer c.mapsMu.RUnlock()
+	for code, tiles := range c.MapsByCode {
+		for _, tile := range tiles {
+			if tile == coords {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// dropsAt returns the drop table of the monster or resource at coords.
+func (c *Svc) dropsAt(coords Coordinates) []Drop {
+	code := c.contentAt(coords)
+	if monster, found := c.GetMonster(code); found {
+		return monster.Drops
+	}
+	for _, resources := range c.ResourcesByDropCode {
+		for _, resource := range resources {
+			if resource.Code == code {
+				return resource.Drops
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	npcCurrencyGold = "gold"
+
+	npcSideBuy  = "buy"
+	npcSideSell = "sell"
+)
+
+type NPC struct {
+	Name        string `json:"name"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// NPCItem is an item an NPC trades. A zero price means the NPC doesn't trade that way.
+type NPCItem struct {
+	Code      string `json:"code"`
+	NPC       string `json:"npc"`
+	Currency  string `json:"currency"`
+	BuyPrice  int    `json:"buy_price"`
+	SellPrice int    `json:"sell_price"`
+}
+
+type NPCsResponse struct {
+	Data  []NPC `json:"data"`
+	Total int   `json:"total"`
+	Page  int   `json:"page"`
+	Size  int   `json:"size"`
+	Pages int   `json:"pages"`
+}
+
+type NPCItemsResponse struct {
+	Data  []NPCItem `json:"data"`
+	Total int       `json:"total"`
+	Page  int       `json:"page"`
+	Size  int       `json:"size"`
+	Pages int       `json:"pages"`
+}
+
+type NPCTransactionResponse struct {
+	Data  NPCTransactionData `json:"data"`
+	Error ErrorMessage       `json:"error"`
+}
+
+type NPCTransactionData struct {
+	Cooldown    Cooldown       `json:"cooldown"`
+	Transaction NPCTransaction `json:"transaction"`
+	Character   Character      `json:"character"`
+}
+
+type NPCTransaction struct {
+	Code       string `json:"code"`
+	Quantity   int    `json:"quantity"`
+	Currency   string `json:"currency"`
+	Price      int    `json:"price"`
+	TotalPrice int    `json:"total_price"`
+}
+
+func (c *ArtifactsClient) GetNPCs(pageNumber int) ([]NPC, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/npcs", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing GetNPCs request: %w", err)
+	}
+	npcResp := NPCsResponse{}
+	if err := json.Unmarshal(respBytes, &npcResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling body: %w", err)
+	}
+	return npcResp.Data, nil
+}
+
+func (c *ArtifactsClient) GetNPCItems(pageNumber int) ([]NPCItem, error) {
+	p := map[string]string{
+		"size": strconv.Itoa(100),
+		"page": strconv.Itoa(pageNumber),
+	}
+	respBytes, err := c.Do(http.MethodGet, "/npcs/items", p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing GetNPCItems request: %w", err)
+	}
+	itemsResp := NPCItemsResponse{}
+	if err := json.Unmarshal(respBytes, &itemsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling body: %w", err)
+	}
+	return itemsResp.Data, nil
+}
+
+func (c *ArtifactsClient) NPCTrade(characterName, side, code string, quantity int) (*NPCTransactionData, error) {
+	path := fmt.Sprintf("/my/%s/action/npc/%s", characterName, side)
+	body := SimpleItem{
+		Code:     code,
+		Quantity: quantity,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	respBytes, err := c.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("executing npc %s request: %w", side, err)
+	}
+
+	tradeResp := NPCTransactionResponse{}
+	if err := json.Unmarshal(respBytes, &tradeResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if tradeResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", tradeResp.Error.Code, tradeResp.Error.Message)
+	}
+	return &tradeResp.Data, nil
+}
+
+func (c *Svc) populateNPCs() error {
+	for i := 1; i < 100; i++ {
+		npcs, err := c.Client.GetNPCs(i)
+		if err != nil {
+			return fmt.Errorf("getting npcs page %d: %w", i, err)
+		}
+		if len(npcs) == 0 {
+			break
+		}
+		for _, npc := range npcs {
+			c.NPCs[npc.Code] = npc
+		}
+	}
+
+	for i := 1; i < 100; i++ {
+		items, err := c.Client.GetNPCItems(i)
+		if err != nil {
+			return fmt.Errorf("getting npc items page %d: %w", i, err)
+		}
+		if len(items) == 0 {
+			break
+		}
+		for _, item := range items {
+			c.NPCItemsByCode[item.Code] = append(c.NPCItemsByCode[item.Code], item)
+		}
+	}
+
+	fmt.Println("NPCs successfully populated")
+	return nil
+}
+
+func (c *Svc) GetNPC(code string) (NPC, bool) {
+	npc, ok := c.NPCs[code]
+	return npc, ok
+}
+
+// GetNPCStock lists the items the NPC trades.
+func (c *Svc) GetNPCStock(npcCode string) []NPCItem {
+	stock := []NPCItem{}
+	for _, offers := range c.NPCItemsByCode {
+		for _, offer := range offers {
+			if offer.NPC == npcCode {
+				stock = append(stock, offer)
+			}
+		}
+	}
+	return stock
+}
+
+// bestNPCOffer returns the NPC on the map with the best gold price for buying or
+// selling code.
+func (c *Svc) bestNPCOffer(code, side string) (NPCItem, bool) {
+	var best NPCItem
+	found := false
+	for _, offer := range c.NPCItemsByCode[code] {
+		if offer.Currency != npcCurrencyGold || len(c.GetCoordinatesByCode(offer.NPC)) == 0 {
+			continue
+		}
+		switch {
+		case side == npcSideBuy && offer.BuyPrice > 0 && (!found || offer.BuyPrice < best.BuyPrice):
+		case side == npcSideSell && offer.SellPrice > 0 && (!found || offer.SellPrice > best.SellPrice):
+		default:
+			continue
+		}
+		best, found = offer, true
+	}
+	return best, found
+}
+
+// BuyFromNPC moves the character to the NPC selling code for the least gold and buys
+// quantity of it.
+func (c *Svc) BuyFromNPC(characterName, code string, quantity int) (*NPCTransaction, error) {
+	offer, found := c.bestNPCOffer(code, npcSideBuy)
+	if !found {
+		return nil, fmt.Errorf("no npc sells %s for gold", code)
+	}
+	return c.tradeWithNPC(characterName, offer.NPC, npcSideBuy, code, quantity)
+}
+
+// SellToNPC moves the character to the NPC paying the most gold for code and sells
+// quantity of it.
+func (c *Svc) SellToNPC(characterName, code string, quantity int) (*NPCTransaction, error) {
+	offer, found := c.bestNPCOffer(code, npcSideSell)
+	if !found {
+		return nil, fmt.Errorf("no npc buys %s for gold", code)
+	}
+	return c.tradeWithNPC(characterName, offer.NPC, npcSideSell, code, quantity)
+}
+
+func (c *Svc) tradeWithNPC(characterName, npcCode, side, code string, quantity int) (*NPCTransaction, error) {
+	if err := c.moveToContent(characterName, npcCode); err != nil {
+		return nil, fmt.Errorf("moving to %s: %w", npcCode, err)
+	}
+
+	fmt.Printf("%s trading with %s: %s %d %s\n", characterName, npcCode, side, quantity, code)
+	tradeData, err := c.Client.NPCTrade(characterName, side, code, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("trading with %s: %w", npcCode, err)
+	}
+	c.setCharacter(characterName, &tradeData.Character)
+	fmt.Printf("%s %d %s for %d %s\n", side, tradeData.Transaction.Quantity, code, tradeData.Transaction.TotalPrice, tradeData.Transaction.Currency)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return &tradeData.Transaction, nil
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const pricesFile = "prices.jsonl"
+
+// PriceSample is one recorded observation of an item's Grand Exchange orders.
+type PriceSample struct {
+	Code        string    `json:"code"`
+	Time        time.Time `json:"time"`
+	LowestAsk   int       `json:"lowest_ask"`
+	AskQuantity int       `json:"ask_quantity"`
+	HighestBid  int       `json:"highest_bid"`
+	BidQuantity int       `json:"bid_quantity"`
+}
+
+// PriceStats summarises the samples of an item over a window. Ask and bid figures
+// only count samples that had orders on that side.
+type PriceStats struct {
+	Code    string
+	Samples int
+	MinAsk  int
+	MaxAsk  int
+	AvgAsk  float64
+	MinBid  int
+	MaxBid  int
+	AvgBid  float64
+	// AvgSpread averages the ask-bid gap over samples with both sides.
+	AvgSpread float64
+}
+
+type PriceRecorder struct {
+	mu      sync.Mutex
+	watched map[string]bool
+}
+
+func NewPriceRecorder() PriceRecorder {
+	return PriceRecorder{
+		mu:      sync.Mutex{},
+		watched: make(map[string]bool),
+	}
+}
+
+// WatchPrices adds items to those the price recorder samples.
+func (c *Svc) WatchPrices(codes ...string) {
+	c.Prices.mu.Lock()
+	defer c.Prices.mu.Unlock()
+	for _, code := range codes {
+		c.Prices.watched[code] = true
+	}
+}
+
+// StartPriceRecorder samples the watched items every interval in the background
+// until the returned stop function is called.
+func (c *Svc) StartPriceRecorder(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.recordPrices(time.Now()); err != nil {
+				fmt.Printf("Recording prices: %v\n", err)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+func (c *Svc) recordPrices(now time.Time) error {
+	c.Prices.mu.Lock()
+	codes := make([]string, 0, len(c.Prices.watched))
+	for code := range c.Prices.watched {
+		codes = append(codes, code)
+	}
+	c.Prices.mu.Unlock()
+	if len(codes) == 0 {
+		return nil
+	}
+	sort.Strings(codes)
+
+	prices, err := c.GEPrices()
+	if err != nil {
+		return fmt.Errorf("getting prices: %w", err)
+	}
+
+	c.Prices.mu.Lock()
+	defer c.Prices.mu.Unlock()
+	path := filepath.Join(c.DataDir, pricesFile)
+	for _, code := range codes {
+		price, listed := prices[code]
+		if !listed {
+			// an empty sample would read as a price crash
+			continue
+		}
+		sample := PriceSample{
+			Code:        code,
+			Time:        now,
+			LowestAsk:   price.LowestAsk,
+			AskQuantity: price.AskQuantity,
+			HighestBid:  price.HighestBid,
+			BidQuantity: price.BidQuantity,
+		}
+		if err := appendJSONLine(path, sample); err != nil {
+			return fmt.Errorf("saving %s sample: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// PriceHistory returns the recorded samples of code taken at or after since, oldest first.
+func (c *Svc) PriceHistory(code string, since time.Time) ([]PriceSample, error) {
+	c.Prices.mu.Lock()
+	defer c.Prices.mu.Unlock()
+	return readPriceHistory(c.DataDir, code, since)
+}
+
+// GetPriceStats summarises the samples of code recorded over the trailing window,
+// giving its moving averages, extremes and spread.
+func (c *Svc) GetPriceStats(code string, window time.Duration) (*PriceStats, error) {
+	samples, err := c.PriceHistory(code, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("getting %s history: %w", code, err)
+	}
+	return priceStats(code, samples), nil
+}
+
+// ReadPriceStats is GetPriceStats for the price store in dataDir, for reading the
+// recorded history without connecting to the server.
+func ReadPriceStats(dataDir, code string, window time.Duration) (*PriceStats, error) {
+	samples, err := readPriceHistory(dataDir, code, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("getting %s history: %w", code, err)
+	}
+	return priceStats(code, samples), nil
+}
+
+func readPriceHistory(dataDir, code string, since time.Time) ([]PriceSample, error) {
+	samples := []PriceSample{}
+	err := readJSONLines(filepath.Join(dataDir, pricesFile), func(line []byte) error {
+		sample := PriceSample{}
+		if err := json.Unmarshal(line, &sample); err != nil {
+			return fmt.Errorf("unmarshalling sample: %w", err)
+		}
+		if sample.Code == code && !sample.Time.Before(since) {
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading price history: %w", err)
+	}
+	return samples, nil
+}
+
+func priceStats(code string, samples []PriceSample) *PriceStats {
+	stats := &PriceStats{Code: code, Samples: len(samples)}
+	asks, bids, spreads := 0, 0, 0
+	for _, sample := range samples {
+		if sample.LowestAsk > 0 {
+			if asks == 0 || sample.LowestAsk < stats.MinAsk {
+				stats.MinAsk = sample.LowestAsk
+			}
+			if sample.LowestAsk > stats.MaxAsk {
+				stats.MaxAsk = sample.LowestAsk
+			}
+			stats.AvgAsk += float64(sample.LowestAsk)
+			asks++
+		}
+		if sample.HighestBid > 0 {
+			if bids == 0 || sample.HighestBid < stats.MinBid {
+				stats.MinBid = sample.HighestBid
+			}
+			if sample.HighestBid > stats.MaxBid {
+				stats.MaxBid = sample.HighestBid
+			}
+			stats.AvgBid += float64(sample.HighestBid)
+			bids++
+		}
+		if sample.LowestAsk > 0 && sample.HighestBid > 0 {
+			stats.AvgSpread += float64(sample.LowestAsk - sample.HighestBid)
+			spreads++
+		}
+	}
+	if asks > 0 {
+		stats.AvgAsk /= float64(asks)
+	}
+	if bids > 0 {
+		stats.AvgBid /= float64(bids)
+	}
+	if spreads > 0 {
+		stats.AvgSpread /= float64(spreads)
+	}
+	return stats
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 )
 
 type RecycleResponse struct {
@@ -21,42 +22,232 @@ type RecycleDetails struct {
 	Items []SimpleItem `json:"items"`
 }
 
-func (c *Svc) RecycleItems(characterName string) error {
-	inventory := c.Characters[characterName].Inventory
-	for _, item := range inventory {
-		if item.Code == "" {
-			break
+// RecycleRule matches inventory items eligible for recycling. Empty fields match
+// every item.
+type RecycleRule struct {
+	Type     string
+	MaxLevel int
+	Codes    []string
+}
+
+// RecyclePolicy decides which inventory items get recycled.
+type RecyclePolicy struct {
+	Rules []RecycleRule
+	// Keep lists item codes that are never recycled.
+	Keep []string
+	// KeepBest keeps this many of the best items of each equipment type, counting
+	// what the character is wearing.
+	KeepBest int
+}
+
+type RecycleEntry struct {
+	Code            string
+	Quantity        int
+	Workshop        Skill
+	ExpectedReturns []SimpleItem
+}
+
+// RecyclePlan lists what a recycling run would recycle, grouped by workshop.
+type RecyclePlan struct {
+	Entries []RecycleEntry
+}
+
+func (r RecycleRule) matches(item CraftableItem) bool {
+	if r.Type != "" && r.Type != item.Type {
+		return false
+	}
+	if r.MaxLevel > 0 && item.Level > r.MaxLevel {
+		return false
+	}
+	if len(r.Codes) > 0 && indexOf(r.Codes, item.Code) < 0 {
+		return false
+	}
+	return true
+}
+
+// isRecyclable reports whether item is gear made in a workshop that accepts recycling.
+func isRecyclable(item CraftableItem) bool {
+	if item.Craft == nil {
+		return false
+	}
+	switch item.Craft.Skill {
+	case SkillWeaponcrafting, SkillGearcrafting, SkillJewelrycrafting:
+		return true
+	}
+	return false
+}
+
+// PlanRecycling works out what RecycleInventory would recycle without doing it.
+func (c *Svc) PlanRecycling(characterName string, policy RecyclePolicy) (*RecyclePlan, error) {
+	character := c.GetCharacterByName(characterName)
+	// another character's recipe may need what this one holds
+	reserved := c.accountReservedItems()
+	kept := c.keepBest(character, policy.KeepBest)
+
+	plan := &RecyclePlan{}
+	for _, slot := range character.Inventory {
+		if slot.Code == "" || slot.Quantity <= 0 {
+			continue
+		}
+		item := c.GetItem(slot.Code)
+		if !isRecyclable(item) || indexOf(policy.Keep, item.Code) >= 0 || reserved[item.Code] {
+			continue
+		}
+
+		matched := false
+		for _, rule := range policy.Rules {
+			if rule.matches(item) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		quantity := slot.Quantity - kept[item.Code]
+		if quantity <= 0 {
+			continue
+		}
+		plan.Entries = append(plan.Entries, RecycleEntry{
+			Code:            item.Code,
+			Quantity:        quantity,
+			Workshop:        item.Craft.Skill,
+			ExpectedReturns: expectedRecycleReturns(item, quantity),
+		})
+	}
+
+	// visit each workshop once
+	sort.Slice(plan.Entries, func(i, j int) bool {
+		if plan.Entries[i].Workshop != plan.Entries[j].Workshop {
+			return plan.Entries[i].Workshop < plan.Entries[j].Workshop
 		}
+		return plan.Entries[i].Code < plan.Entries[j].Code
+	})
+	return plan, nil
+}
+
+// RecycleInventory recycles the inventory items selected by policy. With dryRun set
+// the plan is only reported.
+func (c *Svc) RecycleInventory(characterName string, policy RecyclePolicy, dryRun bool) (*RecyclePlan, error) {
+	plan, err := c.PlanRecycling(characterName, policy)
+	if err != nil {
+		return nil, fmt.Errorf("planning recycling: %w", err)
+	}
 
-		i := c.GetItem(item.Code)
-		contentCode := i.Craft.Skill
-		coords := c.GetCoordinatesByCode(contentCode)
-		if _, err := c.MoveCharacter(characterName, coords[0].X, coords[0].Y); err != nil {
-			return fmt.Errorf("moving to bank: %w", err)
+	for _, entry := range plan.Entries {
+		fmt.Printf("%s recycle %d %s at %s, expecting %v\n", characterName, entry.Quantity, entry.Code, entry.Workshop, entry.ExpectedReturns)
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	for _, entry := range plan.Entries {
+		if _, err := c.recycle(characterName, entry.Code, entry.Quantity); err != nil {
+			return nil, fmt.Errorf("recycling %s: %w", entry.Code, err)
 		}
+	}
+	return plan, nil
+}
+
+// keepBest returns how many of each item code are protected by the keep-best rule.
+func (c *Svc) keepBest(character *Character, n int) map[string]int {
+	kept := map[string]int{}
+	if n <= 0 {
+		return kept
+	}
 
-		path := fmt.Sprintf("/my/%s/action/recycling", characterName)
-		body := SimpleItem{
-			Code:     item.Code,
-			Quantity: item.Quantity,
+	owned := map[string]int{}
+	for _, slot := range character.Inventory {
+		if slot.Code != "" {
+			owned[slot.Code] += slot.Quantity
 		}
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("marshalling body: %w", err)
+	}
+	equipped := map[string]int{}
+	for _, item := range character.EquippedItems() {
+		owned[item.Code] += item.Quantity
+		equipped[item.Code] += item.Quantity
+	}
+
+	byType := map[string][]string{}
+	for code := range owned {
+		if item := c.GetItem(code); item.IsEquippable() {
+			byType[item.Type] = append(byType[item.Type], code)
 		}
-		resp, err := c.Client.Do(http.MethodPost, path, nil, bodyBytes)
-		if err != nil {
-			return fmt.Errorf("executing recycle %s request: %w", item.Code, err)
+	}
+	for _, codes := range byType {
+		sort.Slice(codes, func(i, j int) bool {
+			si := c.scoreItem(character, c.GetItem(codes[i]), GearTarget{})
+			sj := c.scoreItem(character, c.GetItem(codes[j]), GearTarget{})
+			if si != sj {
+				return si > sj
+			}
+			return codes[i] < codes[j]
+		})
+
+		remaining := n
+		for _, code := range codes {
+			if remaining <= 0 {
+				break
+			}
+			take := owned[code]
+			if take > remaining {
+				take = remaining
+			}
+			remaining -= take
+			// worn copies are never in the inventory so only protect the rest
+			if inBag := take - equipped[code]; inBag > 0 {
+				kept[code] = inBag
+			}
 		}
+	}
+	return kept
+}
 
-		recycleResp := RecycleResponse{}
-		if err := json.Unmarshal(resp, &recycleResp); err != nil {
-			return fmt.Errorf("unmarshalling response: %w", err)
+// expectedRecycleReturns estimates the materials recycling gives back, roughly half
+// of the recipe for each item.
+func expectedRecycleReturns(item CraftableItem, quantity int) []SimpleItem {
+	out := []SimpleItem{}
+	for _, ingredient := range item.Craft.Items {
+		if expected := ingredient.Quantity * quantity / 2; expected > 0 {
+			out = append(out, SimpleItem{Code: ingredient.Code, Quantity: expected})
 		}
+	}
+	return out
+}
 
-		c.Characters[characterName] = &recycleResp.Data.Character
-		c.Characters[characterName].WaitForCooldown()
+// recycle moves the character to the workshop that crafts code and recycles quantity of it.
+func (c *Svc) recycle(characterName, code string, quantity int) (*RecycleData, error) {
+	i := c.GetItem(code)
+	if !isRecyclable(i) {
+		return nil, fmt.Errorf("%s cannot be recycled", code)
+	}
+	if err := c.moveToContent(characterName, string(i.Craft.Skill)); err != nil {
+		return nil, fmt.Errorf("moving to workshop: %w", err)
 	}
 
-	return nil
+	path := fmt.Sprintf("/my/%s/action/recycling", characterName)
+	body := SimpleItem{
+		Code:     code,
+		Quantity: quantity,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	resp, err := c.Client.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("executing recycle %s request: %w", code, err)
+	}
+
+	recycleResp := RecycleResponse{}
+	if err := json.Unmarshal(resp, &recycleResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling response: %w", err)
+	}
+	fmt.Printf("%s recycled %d %s into %v\n", characterName, quantity, code, recycleResp.Data.Details.Items)
+
+	c.setCharacter(characterName, &recycleResp.Data.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return &recycleResp.Data, nil
 }
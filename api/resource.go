@@ -15,7 +15,7 @@ type ResourceResponse struct {
 type ResourceData struct {
 	Name  string `json:"name"`
 	Code  string `json:"code"`
-	Skill string `json:"skill"`
+	Skill Skill  `json:"skill"`
 	Level int    `json:"level"`
 	Drops []Drop `json:"drops"`
 
@@ -67,3 +67,69 @@ func (c *ArtifactsClient) Gather(characterName string) (*SkillData, error) {
 
 	return &gatherResp.Data, nil
 }
+
+// ExpectedYield returns the average quantity of dropCode received per gather.
+// Drop rates are expressed as a 1 in Rate chance.
+func (r ResourceData) ExpectedYield(dropCode string) float64 {
+	for _, drop := range r.Drops {
+		if drop.Code != dropCode || drop.Rate <= 0 {
+			continue
+		}
+		return float64(drop.MinQuantity+drop.MaxQuantity) / 2 / float64(drop.Rate)
+	}
+	return 0
+}
+
+// SelectResource picks the resource the character should harvest to obtain dropCode.
+// Resources above the character's skill level are skipped, then the highest expected
+// yield wins with ties going to the closest tile.
+func (c *Svc) SelectResource(characterName, dropCode string) (*ResourceData, Coordinates, error) {
+	resources := c.GetResourceByCode(dropCode)
+	if len(resources) == 0 {
+		return nil, Coordinates{}, fmt.Errorf("no resource drops %s", dropCode)
+	}
+
+	character := c.GetCharacterByName(characterName)
+	var best *ResourceData
+	var bestCoords Coordinates
+	var bestYield float64
+	bestDistance := 0
+
+	// track the lowest requirement we could not meet for the error message
+	var requiredSkill Skill
+	requiredLevel := 0
+
+	for i := range resources {
+		resource := resources[i]
+		if !character.AbleToCraft(resource.Skill, resource.Level) {
+			if requiredSkill == "" || resource.Level < requiredLevel {
+				requiredSkill = resource.Skill
+				requiredLevel = resource.Level
+			}
+			continue
+		}
+
+		coords, distance, found := c.nearestCoordinates(characterName, resource.Code)
+		if !found {
+			continue
+		}
+
+		yield := resource.ExpectedYield(dropCode)
+		if best == nil || yield > bestYield || (yield == bestYield && distance < bestDistance) {
+			best = &resource
+			bestCoords = coords
+			bestYield = yield
+			bestDistance = distance
+		}
+	}
+
+	if best == nil {
+		if requiredSkill != "" {
+			return nil, Coordinates{}, fmt.Errorf("unable to harvest %s: requires %s level %d", dropCode, requiredSkill, requiredLevel)
+		}
+		return nil, Coordinates{}, fmt.Errorf("no map tile found for resources dropping %s", dropCode)
+	}
+
+	fmt.Printf("%s selected resource %s for %s\n", characterName, best.Code, dropCode)
+	return best, bestCoords, nil
+}
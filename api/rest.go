@@ -34,8 +34,8 @@ func (c *Svc) Rest(characterName string) error {
 		return fmt.Errorf("error response received: status code: %d, error message: %s", restResp.Error.Code, restResp.Error.Message)
 	}
 
-	c.Characters[characterName] = &restResp.Rest.Character
-	c.Characters[characterName].WaitForCooldown()
+	c.setCharacter(characterName, &restResp.Rest.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 
 	return nil
 }
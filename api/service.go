@@ -3,53 +3,141 @@ package api
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 type Service interface {
 	Fight(characterName string) (*FightResponse, error)
 	ContinuousFightLoop(characterName string) error
 	Rest(characterName string) error
+	EstimateFight(characterName, monsterCode string) (*FightEstimate, error)
+	Heal(characterName string) error
+	Use(characterName, code string, quantity int) error
+	SetHealPolicy(characterName string, policy HealPolicy)
 
 	AcceptTask(characterName string) (*AcceptTaskResponse, error)
 	CompleteTask(characterName string) (*CompleteTaskResponse, error)
+	CancelTask(characterName string) (*CancelTaskResponse, error)
+	RunMonsterTasks(characterName string, opts TaskRunOptions) error
+	RunItemTasks(characterName string, opts TaskRunOptions) error
+	RunTasks(characterName, taskType string, opts TaskRunOptions) error
+	TradeTaskItem(characterName, code string, quantity int) (*TaskTradeResponse, error)
+	ExchangeTaskCoins(characterName string) (*TaskExchangeResponse, error)
+	SetTaskPolicy(characterName string, policy TaskPolicy)
+	GetTaskLedger(characterName string) TaskLedger
 
 	MoveCharacter(characterName string, x, y int) (*MoveResponse, error)
 
-	Equip(characterName string, item CraftableItem) error
-	Unequip(characterName string, item CraftableItem) error
+	Equip(characterName string, item CraftableItem, slot Slot, quantity int) error
+	Unequip(characterName string, slot Slot, quantity int) error
+	BestLoadout(characterName string, target GearTarget) (Loadout, error)
+	ApplyLoadout(characterName string, loadout Loadout) error
+	OptimizeGear(characterName string, target GearTarget) error
+	GetLoadout(characterName, name string) (Loadout, bool)
+	SaveLoadout(characterName, name string, loadout Loadout) error
+	SwitchLoadout(characterName, name string) (Loadout, error)
+	SetAutoEquipPolicy(characterName string, policy AutoEquipPolicy)
+	EquipPendingUpgrades(characterName string) error
+	SetUtilityPolicy(characterName string, policy UtilityPolicy)
+	StockUtilities(characterName string) error
+	UtilityConsumption(characterName string) map[string]int
 
 	CraftItem(characterName, code string, quantity int) (*CraftableItem, error)
 	Craft(characterName, code string, quantity int) error
-	RecycleItems(characterName string) error
+	PlanRecycling(characterName string, policy RecyclePolicy) (*RecyclePlan, error)
+	RecycleInventory(characterName string, policy RecyclePolicy, dryRun bool) (*RecyclePlan, error)
 	Gather(characterName string, item CraftableItem, quantity int) error
+	SelectResource(characterName, dropCode string) (*ResourceData, Coordinates, error)
 	//GatherLoop(characterName, code string) error
 	GatherLoop(characterName, code string, quantity int) error
 	FightForCrafting(characterName, dropCode string, quantity *int) error
+	LevelSkill(characterName string, skill Skill, targetLevel int) error
+	BestActivity(characterName string, skill Skill) (*Activity, error)
 
 	GetBankItems() ([]SimpleItem, error)
 	GetBankItemsByCode(code string) (SimpleItem, bool)
 	DepositAllItems(characterName string) error
+	DepositGold(characterName string, quantity int) error
+	SetInventoryPolicy(characterName string, policy InventoryPolicy)
+	NeedsBankTrip(characterName string) bool
 	DepositBank(characterName string, inventoryItem InventorySlot) error
 	WithdrawBankItem(characterName, itemCode string, quantity int) error
 	WithdrawFromBankIfFound(characterName, itemCode string, quantity int) (int, error)
 
+	GEOrders(code string) ([]GEOrder, error)
+	GEPrices() (map[string]GEPrice, error)
+	GetGEPrice(code string) (GEPrice, error)
+	MyGEOrders() ([]GEOrder, error)
+	GEHistory() ([]GETrade, error)
+	SellOnGE(characterName, code string, quantity, price int) (*GEOrder, error)
+	BuyOnGE(characterName, orderID string, quantity int) (*GETransaction, error)
+	CancelGEOrder(characterName, id string) error
+	GetNPC(code string) (NPC, bool)
+	GetNPCStock(npcCode string) []NPCItem
+	BuyFromNPC(characterName, code string, quantity int) (*NPCTransaction, error)
+	SellToNPC(characterName, code string, quantity int) (*NPCTransaction, error)
+	SetEconomyPolicy(policy EconomyPolicy)
+	PlanSurplus(policy SurplusPolicy) (*SurplusPlan, error)
+	SellSurplus(characterName string, policy SurplusPolicy, dryRun bool) (*SurplusPlan, int, error)
+	WatchPrices(codes ...string)
+	StartPriceRecorder(interval time.Duration) func()
+	PriceHistory(code string, since time.Time) ([]PriceSample, error)
+	GetPriceStats(code string, window time.Duration) (*PriceStats, error)
+	GoldSpentOnInputs() int
+
+	RefreshEvents() error
+	StartEventPoller(interval time.Duration) func()
+	ActiveEvents() []ActiveEvent
+	OnEvent(fn func(EventNotification))
+
+	CheckServerStatus() (*ServerStatus, error)
+	ClockOffset() time.Duration
+	GetAccountDetails() (*AccountDetails, error)
+	GetAchievements(account string) ([]Achievement, error)
+	ClosestAchievements(n int) ([]Achievement, error)
+	SyncLogs() (int, error)
+	GetLogs(filter LogFilter) ([]LogEntry, error)
+
 	GetAllCharacters() map[string]*Character
+	CreateCharacter(name, skin string) (*Character, error)
+	DeleteCharacter(name string) error
 	GetCharacterByName(characterName string) *Character
 	GetCoordinatesByCode(contentCode string) []Coordinates
 	GetItem(code string) CraftableItem
+	GetMonster(code string) (MonsterData, bool)
 	GetMonsterByDrop(dropCode string) []MonsterData
 	GetMonsterByLevel(level int) []MonsterData
 }
 
 type Svc struct {
 	Characters          map[string]*Character
+	charactersMu        sync.Mutex
+	loops               map[string]int
 	Client              Client
 	MapsByCode          map[string][]Coordinates
+	mapsMu              sync.RWMutex
 	Items               map[string]CraftableItem
+	MonstersByCode      map[string]MonsterData
 	MonstersByDrop      map[string][]MonsterData
 	MonstersByLevel     map[int][]MonsterData
 	ResourcesByDropCode map[string][]ResourceData
+	NPCs                map[string]NPC
+	NPCItemsByCode      map[string][]NPCItem
 	Bank                Bank
+	Activities          ActivityStats
+	Loadouts            Loadouts
+	AutoEquip           AutoEquip
+	Utilities           Utilities
+	Healing             Healing
+	Reservations        Reservations
+	Inventories         Inventories
+	TaskEconomy         TaskEconomy
+	Economy             Economy
+	Prices              PriceRecorder
+	Events              Events
+	Logs                ActionLogs
+	Clock               ServerClock
+	DataDir             string
 }
 
 type Bank struct {
@@ -60,15 +148,36 @@ type Bank struct {
 func NewSvc(token string) (Service, error) {
 	svc := &Svc{
 		Characters:          make(map[string]*Character),
+		loops:               make(map[string]int),
 		Client:              NewClient(token),
 		MapsByCode:          make(map[string][]Coordinates),
 		Items:               make(map[string]CraftableItem),
+		MonstersByCode:      make(map[string]MonsterData),
 		MonstersByDrop:      make(map[string][]MonsterData),
 		MonstersByLevel:     make(map[int][]MonsterData),
 		ResourcesByDropCode: make(map[string][]ResourceData),
+		NPCs:                make(map[string]NPC),
+		NPCItemsByCode:      make(map[string][]NPCItem),
 		Bank:                NewBank(),
+		Activities:          NewActivityStats(),
+		Loadouts:            NewLoadouts(),
+		AutoEquip:           NewAutoEquip(),
+		Utilities:           NewUtilities(),
+		Healing:             NewHealing(),
+		Reservations:        NewReservations(),
+		Inventories:         NewInventories(),
+		TaskEconomy:         NewTaskEconomy(),
+		Economy:             NewEconomy(),
+		Prices:              NewPriceRecorder(),
+		Events:              NewEvents(),
+		Logs:                NewActionLogs(),
+		Clock:               NewServerClock(),
+		DataDir:             DefaultDataDir,
 	}
 
+	if _, err := svc.CheckServerStatus(); err != nil {
+		return nil, fmt.Errorf("checking server status: %w", err)
+	}
 	if err := svc.populateMaps(); err != nil {
 		return nil, fmt.Errorf("populating maps: %w", err)
 	}
@@ -81,12 +190,24 @@ func NewSvc(token string) (Service, error) {
 	if err := svc.populateResources(); err != nil {
 		return nil, fmt.Errorf("populating resources: %w", err)
 	}
+	if err := svc.populateNPCs(); err != nil {
+		return nil, fmt.Errorf("populating npcs: %w", err)
+	}
+	if err := svc.RefreshEvents(); err != nil {
+		return nil, fmt.Errorf("populating events: %w", err)
+	}
 	if err := svc.populateCharacters(); err != nil {
 		return nil, fmt.Errorf("populating characters: %w", err)
 	}
 	if err := svc.populateBank(); err != nil {
 		return nil, fmt.Errorf("populating bank: %w", err)
 	}
+	if err := svc.populateLoadouts(); err != nil {
+		return nil, fmt.Errorf("populating loadouts: %w", err)
+	}
+	if err := svc.populateTaskLedgers(); err != nil {
+		return nil, fmt.Errorf("populating task ledgers: %w", err)
+	}
 	return svc, nil
 }
 
@@ -98,14 +219,35 @@ func NewBank() Bank {
 }
 
 func (c *Svc) GetCharacterByName(characterName string) *Character {
+	c.charactersMu.Lock()
+	defer c.charactersMu.Unlock()
 	return c.Characters[characterName]
 }
 
+// setCharacter stores the latest state of a character returned by the server.
+func (c *Svc) setCharacter(characterName string, character *Character) {
+	character.clockOffset = c.ClockOffset()
+	c.charactersMu.Lock()
+	defer c.charactersMu.Unlock()
+	c.Characters[characterName] = character
+}
+
+// GetAllCharacters returns a snapshot of the account's characters that stays valid
+// while characters are created or deleted.
 func (c *Svc) GetAllCharacters() map[string]*Character {
-	return c.Characters
+	c.charactersMu.Lock()
+	defer c.charactersMu.Unlock()
+
+	out := make(map[string]*Character, len(c.Characters))
+	for name, character := range c.Characters {
+		out[name] = character
+	}
+	return out
 }
 
 func (c *Svc) GetCoordinatesByCode(contentCode string) []Coordinates {
+	c.mapsMu.RLock()
+	defer c.mapsMu.RUnlock()
 	return c.MapsByCode[contentCode]
 }
 
@@ -113,11 +255,22 @@ func (c *Svc) GetItem(code string) CraftableItem {
 	return c.Items[code]
 }
 
+func (c *Svc) GetMonster(code string) (MonsterData, bool) {
+	c.mapsMu.RLock()
+	defer c.mapsMu.RUnlock()
+	monster, ok := c.MonstersByCode[code]
+	return monster, ok
+}
+
 func (c *Svc) GetMonsterByDrop(dropCode string) []MonsterData {
+	c.mapsMu.RLock()
+	defer c.mapsMu.RUnlock()
 	return c.MonstersByDrop[dropCode]
 }
 
 func (c *Svc) GetMonsterByLevel(level int) []MonsterData {
+	c.mapsMu.RLock()
+	defer c.mapsMu.RUnlock()
 	return c.MonstersByLevel[level]
 }
 
@@ -130,13 +283,25 @@ func (c *Svc) GetBankItemsByCode(code string) (SimpleItem, bool) {
 	return item, ok
 }
 
+// bankSnapshot returns a copy of the bank quantities keyed by item code.
+func (c *Svc) bankSnapshot() map[string]int {
+	c.Bank.mu.Lock()
+	defer c.Bank.mu.Unlock()
+
+	out := make(map[string]int, len(c.Bank.BankItemsByCode))
+	for code, item := range c.Bank.BankItemsByCode {
+		out[code] = item.Quantity
+	}
+	return out
+}
+
 func (c *Svc) populateCharacters() error {
 	chars, err := c.Client.GetCharacters()
 	if err != nil {
 		return fmt.Errorf("getting characters: %w", err)
 	}
 	for _, char := range chars {
-		c.Characters[char.Name] = char
+		c.setCharacter(char.Name, char)
 	}
 
 	return nil
@@ -197,10 +362,7 @@ func (c *Svc) populateMonsters() error {
 		}
 
 		for _, monster := range monsters {
-			c.MonstersByLevel[monster.Level] = append(c.MonstersByLevel[monster.Level], monster)
-			for _, drop := range monster.Drops {
-				c.MonstersByDrop[drop.Code] = append(c.MonstersByDrop[drop.Code], monster)
-			}
+			c.indexMonster(monster)
 		}
 	}
 
@@ -208,6 +370,40 @@ func (c *Svc) populateMonsters() error {
 	return nil
 }
 
+// indexMonster adds monster to the monster catalogs. Callers hold mapsMu once the
+// service is running.
+func (c *Svc) indexMonster(monster MonsterData) {
+	c.MonstersByCode[monster.Code] = monster
+	c.MonstersByLevel[monster.Level] = append(c.MonstersByLevel[monster.Level], monster)
+	for _, drop := range monster.Drops {
+		c.MonstersByDrop[drop.Code] = append(c.MonstersByDrop[drop.Code], monster)
+	}
+}
+
+// unindexMonster removes the monster with code from the monster catalogs. Callers
+// hold mapsMu.
+func (c *Svc) unindexMonster(code string) {
+	monster, found := c.MonstersByCode[code]
+	if !found {
+		return
+	}
+	delete(c.MonstersByCode, code)
+	c.MonstersByLevel[monster.Level] = withoutMonster(c.MonstersByLevel[monster.Level], code)
+	for _, drop := range monster.Drops {
+		c.MonstersByDrop[drop.Code] = withoutMonster(c.MonstersByDrop[drop.Code], code)
+	}
+}
+
+func withoutMonster(monsters []MonsterData, code string) []MonsterData {
+	out := make([]MonsterData, 0, len(monsters))
+	for _, monster := range monsters {
+		if monster.Code != code {
+			out = append(out, monster)
+		}
+	}
+	return out
+}
+
 func (c *Svc) populateResources() error {
 	for i := 1; i < 100; i++ {
 		fmt.Printf("Populating resources page %d\n", i)
@@ -1,5 +1,7 @@
 package api
 
+import "fmt"
+
 type SkillResponse struct {
 	Data  SkillData    `json:"data"`
 	Error ErrorMessage `json:"error"`
@@ -10,3 +12,90 @@ type SkillData struct {
 	Details   SkillDetails `json:"details"`
 	Character Character    `json:"character"`
 }
+
+type Skill string
+
+const (
+	SkillMining          Skill = "mining"
+	SkillWoodcutting     Skill = "woodcutting"
+	SkillFishing         Skill = "fishing"
+	SkillWeaponcrafting  Skill = "weaponcrafting"
+	SkillGearcrafting    Skill = "gearcrafting"
+	SkillJewelrycrafting Skill = "jewelrycrafting"
+	SkillCooking         Skill = "cooking"
+	SkillAlchemy         Skill = "alchemy"
+	SkillCombat          Skill = "combat"
+)
+
+var Skills = []Skill{
+	SkillMining,
+	SkillWoodcutting,
+	SkillFishing,
+	SkillWeaponcrafting,
+	SkillGearcrafting,
+	SkillJewelrycrafting,
+	SkillCooking,
+	SkillAlchemy,
+	SkillCombat,
+}
+
+// skillStats returns the level, xp and max xp the character has in skill.
+func (c Character) skillStats(skill Skill) (int, int, int) {
+	switch skill {
+	case SkillMining:
+		return c.MiningLevel, c.MiningXP, c.MiningMaxXP
+	case SkillWoodcutting:
+		return c.WoodcuttingLevel, c.WoodcuttingXP, c.WoodcuttingMaxXP
+	case SkillFishing:
+		return c.FishingLevel, c.FishingXP, c.FishingMaxXP
+	case SkillWeaponcrafting:
+		return c.WeaponcraftingLevel, c.WeaponcraftingXP, c.WeaponcraftingMaxXP
+	case SkillGearcrafting:
+		return c.GearcraftingLevel, c.GearcraftingXP, c.GearcraftingMaxXP
+	case SkillJewelrycrafting:
+		return c.JewelrycraftingLevel, c.JewelrycraftingXP, c.JewelrycraftingMaxXP
+	case SkillCooking:
+		return c.CookingLevel, c.CookingXP, c.CookingMaxXP
+	case SkillAlchemy:
+		return c.AlchemyLevel, c.AlchemyXP, c.AlchemyMaxXP
+	case SkillCombat:
+		return c.Level, c.XP, c.MaxXP
+	}
+	return 0, 0, 0
+}
+
+// SkillLevel returns the character's level in skill, or 0 for unknown skills.
+func (c Character) SkillLevel(skill Skill) int {
+	level, _, _ := c.skillStats(skill)
+	return level
+}
+
+func (c Character) SkillXP(skill Skill) int {
+	_, xp, _ := c.skillStats(skill)
+	return xp
+}
+
+func (c Character) SkillMaxXP(skill Skill) int {
+	_, _, maxXp := c.skillStats(skill)
+	return maxXp
+}
+
+func (c Character) XPToNextLevel(skill Skill) int {
+	_, xp, maxXp := c.skillStats(skill)
+	return maxXp - xp
+}
+
+// SkillProgress returns the percentage of the current level completed in skill.
+func (c Character) SkillProgress(skill Skill) float64 {
+	_, xp, maxXp := c.skillStats(skill)
+	if maxXp == 0 {
+		return 0
+	}
+	return float64(xp) / float64(maxXp) * 100.0
+}
+
+// SkillSummary formats the character's progress in skill for logging.
+func (c Character) SkillSummary(skill Skill) string {
+	level, xp, maxXp := c.skillStats(skill)
+	return fmt.Sprintf("%s level %d: %d/%d xp (%.1f%%)", skill, level, xp, maxXp, c.SkillProgress(skill))
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const serverStatusOnline = "online"
+
+// ErrMaintenance is returned by NewSvc when the server is down for maintenance.
+var ErrMaintenance = errors.New("server is under maintenance")
+
+type StatusResponse struct {
+	Data  ServerStatus `json:"data"`
+	Error ErrorMessage `json:"error"`
+}
+
+type ServerStatus struct {
+	Status           string         `json:"status"`
+	Version          string         `json:"version"`
+	MaxLevel         int            `json:"max_level"`
+	CharactersOnline int            `json:"characters_online"`
+	ServerTime       time.Time      `json:"server_time"`
+	Announcements    []Announcement `json:"announcements"`
+	LastWipe         string         `json:"last_wipe"`
+	NextWipe         string         `json:"next_wipe"`
+}
+
+type Announcement struct {
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *ArtifactsClient) GetStatus() (*ServerStatus, error) {
+	respBytes, err := c.Do(http.MethodGet, "/", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing status request: %w", err)
+	}
+
+	statusResp := StatusResponse{}
+	if err := json.Unmarshal(respBytes, &statusResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+	if statusResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", statusResp.Error.Code, statusResp.Error.Message)
+	}
+	return &statusResp.Data, nil
+}
+
+// ServerClock tracks the offset between the server's clock and the local one.
+type ServerClock struct {
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+func NewServerClock() ServerClock {
+	return ServerClock{
+		mu: sync.RWMutex{},
+	}
+}
+
+// serverNow returns the current time on the server's clock.
+func (c *Svc) serverNow() time.Time {
+	return time.Now().Add(c.ClockOffset())
+}
+
+// ClockOffset returns how far the server's clock is ahead of the local one.
+func (c *Svc) ClockOffset() time.Duration {
+	c.Clock.mu.RLock()
+	defer c.Clock.mu.RUnlock()
+	return c.Clock.offset
+}
+
+func (c *Svc) setClockOffset(offset time.Duration) {
+	c.Clock.mu.Lock()
+	c.Clock.offset = offset
+	c.Clock.mu.Unlock()
+
+	// characters already tracked wait out their cooldowns on the new clock
+	c.charactersMu.Lock()
+	defer c.charactersMu.Unlock()
+	for _, character := range c.Characters {
+		character.clockOffset = offset
+	}
+}
+
+// CheckServerStatus fetches the server status, prints its announcements and
+// synchronises the cooldown clock with the server. It returns ErrMaintenance when the
+// server isn't online.
+func (c *Svc) CheckServerStatus() (*ServerStatus, error) {
+	sent := time.Now()
+	status, err := c.Client.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("getting server status: %w", maintenanceError(err))
+	}
+	received := time.Now()
+
+	fmt.Printf("Server %s, version %s, %d characters online\n", status.Status, status.Version, status.CharactersOnline)
+	for _, announcement := range status.Announcements {
+		fmt.Printf("Announcement: %s\n", announcement.Message)
+	}
+	if status.Status == "" {
+		// no status reported, so make sure the game api itself answers
+		if _, err := c.Client.GetAccountDetails(); err != nil {
+			return status, fmt.Errorf("confirming server is online: %w", maintenanceError(err))
+		}
+	} else if status.Status != serverStatusOnline {
+		return status, fmt.Errorf("%w: status %s", ErrMaintenance, status.Status)
+	}
+
+	if !status.ServerTime.IsZero() {
+		// assume the server stamped the response halfway through the round trip
+		offset := status.ServerTime.Sub(sent.Add(received.Sub(sent) / 2))
+		c.setClockOffset(offset)
+		fmt.Printf("Clock offset from server: %v\n", offset)
+	}
+	return status, nil
+}
+
+// maintenanceError wraps err in ErrMaintenance when the server answered with a bad
+// gateway or service unavailable status, which it does while down for maintenance.
+func maintenanceError(err error) error {
+	httpErr := &HTTPError{}
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusBadGateway || httpErr.StatusCode == http.StatusServiceUnavailable) {
+		return fmt.Errorf("%w: %v", ErrMaintenance, err)
+	}
+	return err
+}
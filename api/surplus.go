@@ -0,0 +1,206 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SurplusPolicy decides which bank stock is surplus and the prices it may be sold at.
+type SurplusPolicy struct {
+	// KeepDefault is how many of every item stay in the bank.
+	KeepDefault int
+	// Keep overrides KeepDefault for individual item codes.
+	Keep map[string]int
+	// PlannedRecipes are items the account intends to craft. Nothing used to make
+	// them is ever sold.
+	PlannedRecipes []string
+	// KeepEquippedTier never sells gear at least as good, by level, as what some
+	// character has equipped in a slot that takes it.
+	KeepEquippedTier bool
+	// PriceFloors are the lowest prices items are sold at. Items without a floor are
+	// only sold into existing bids.
+	PriceFloors map[string]int
+}
+
+type SurplusEntry struct {
+	Code     string
+	Quantity int
+	// Price is what each item is listed at.
+	Price int
+	// Bid is set when Price meets an existing bid and the sale should fill at once.
+	Bid bool
+	// NPC is the merchant buying the items instead of the Grand Exchange.
+	NPC string
+}
+
+// SurplusPlan lists the bank stock a liquidation run would sell.
+type SurplusPlan struct {
+	Entries []SurplusEntry
+}
+
+func (p SurplusPolicy) keep(code string) int {
+	if keep, found := p.Keep[code]; found {
+		return keep
+	}
+	return p.KeepDefault
+}
+
+// PlanSurplus works out which bank items SellSurplus would sell and at what price.
+func (c *Svc) PlanSurplus(policy SurplusPolicy) (*SurplusPlan, error) {
+	protected := map[string]bool{}
+	for _, code := range policy.PlannedRecipes {
+		c.recipeInputs(code, protected, 0)
+	}
+	for name := range c.GetAllCharacters() {
+		for code := range c.reservedItems(name) {
+			protected[code] = true
+		}
+	}
+
+	// one listing of the whole order book instead of one per bank item
+	prices, err := c.GEPrices()
+	if err != nil {
+		return nil, fmt.Errorf("getting prices: %w", err)
+	}
+
+	plan := &SurplusPlan{}
+	for code, quantity := range c.bankSnapshot() {
+		item := c.GetItem(code)
+		surplus := quantity - policy.keep(code)
+		if surplus <= 0 || protected[code] {
+			continue
+		}
+		if policy.KeepEquippedTier && c.isEquippedTier(item) {
+			continue
+		}
+
+		price := GEPrice{Code: code}
+		if listed, found := prices[code]; found && item.Tradeable {
+			price = listed
+		}
+		offer, sellable := c.bestNPCOffer(code, npcSideSell)
+		floor := policy.PriceFloors[code]
+		entry := SurplusEntry{Code: code, Quantity: surplus}
+		switch {
+		case sellable && offer.SellPrice >= floor && offer.SellPrice >= price.HighestBid:
+			entry.Price, entry.NPC = offer.SellPrice, offer.NPC
+		case !item.Tradeable:
+			continue
+		case price.HighestBid > 0 && price.HighestBid >= floor:
+			entry.Price, entry.Bid = price.HighestBid, true
+			if entry.Quantity > price.BidQuantity {
+				entry.Quantity = price.BidQuantity
+			}
+		case floor > 0 && price.LowestAsk > floor:
+			// undercut the cheapest listing
+			entry.Price = price.LowestAsk - 1
+		case floor > 0:
+			entry.Price = floor
+		default:
+			continue
+		}
+		plan.Entries = append(plan.Entries, entry)
+	}
+
+	sort.Slice(plan.Entries, func(i, j int) bool {
+		return plan.Entries[i].Code < plan.Entries[j].Code
+	})
+	return plan, nil
+}
+
+// SellSurplus withdraws the surplus bank stock picked by policy and sells it to NPCs
+// or on the Grand Exchange, returning the plan and the gold earned by sales to NPCs
+// and into existing bids. Other listings pay out only when they fill, so they aren't
+// counted. With dryRun set the plan is only reported.
+func (c *Svc) SellSurplus(characterName string, policy SurplusPolicy, dryRun bool) (*SurplusPlan, int, error) {
+	plan, err := c.PlanSurplus(policy)
+	if err != nil {
+		return nil, 0, fmt.Errorf("planning surplus: %w", err)
+	}
+
+	for _, entry := range plan.Entries {
+		where := "on the grand exchange"
+		if entry.NPC != "" {
+			where = "to " + entry.NPC
+		}
+		fmt.Printf("%s sell %d %s at %d gold each %s\n", characterName, entry.Quantity, entry.Code, entry.Price, where)
+	}
+	if dryRun {
+		return plan, 0, nil
+	}
+
+	earned := 0
+	for _, entry := range plan.Entries {
+		remaining := entry.Quantity
+		for remaining > 0 {
+			space := c.GetCharacterByName(characterName).FreeItemSpace() - defaultInventoryBuffer
+			if space <= 0 {
+				if err := c.DepositAllItems(characterName); err != nil {
+					return nil, earned, fmt.Errorf("making room for %s: %w", entry.Code, err)
+				}
+				space = c.GetCharacterByName(characterName).FreeItemSpace() - defaultInventoryBuffer
+			}
+			if space <= 0 {
+				return nil, earned, fmt.Errorf("no inventory space for %s", entry.Code)
+			}
+			batch := remaining
+			if batch > space {
+				batch = space
+			}
+
+			withdrawn, err := c.WithdrawFromBankIfFound(characterName, entry.Code, batch)
+			if err != nil {
+				return nil, earned, fmt.Errorf("withdrawing %s: %w", entry.Code, err)
+			}
+			c.GetCharacterByName(characterName).WaitForCooldown()
+			if withdrawn <= 0 {
+				break
+			}
+
+			gold := c.GetCharacterByName(characterName).Gold
+			if entry.NPC != "" {
+				if _, err := c.tradeWithNPC(characterName, entry.NPC, npcSideSell, entry.Code, withdrawn); err != nil {
+					return nil, earned, fmt.Errorf("selling %s to %s: %w", entry.Code, entry.NPC, err)
+				}
+			} else if _, err := c.SellOnGE(characterName, entry.Code, withdrawn, entry.Price); err != nil {
+				return nil, earned, fmt.Errorf("selling %s: %w", entry.Code, err)
+			}
+			if entry.NPC != "" || entry.Bid {
+				earned += c.GetCharacterByName(characterName).Gold - gold
+			}
+			remaining -= withdrawn
+		}
+	}
+
+	fmt.Printf("%s earned %d gold selling surplus\n", characterName, earned)
+	return plan, earned, nil
+}
+
+// recipeInputs adds code and everything used to craft it to inputs.
+func (c *Svc) recipeInputs(code string, inputs map[string]bool, depth int) {
+	inputs[code] = true
+	item := c.GetItem(code)
+	if item.Craft == nil || depth > maxPlanningDepth {
+		return
+	}
+	for _, subItem := range item.Craft.Items {
+		c.recipeInputs(subItem.Code, inputs, depth+1)
+	}
+}
+
+// isEquippedTier reports whether item is at least the level of the gear some
+// character wears in a slot it fits.
+func (c *Svc) isEquippedTier(item CraftableItem) bool {
+	if !item.IsEquippable() {
+		return false
+	}
+	for _, character := range c.GetAllCharacters() {
+		for _, slot := range SlotsForItemType(item.Type) {
+			equipped := character.EquippedItem(slot)
+			if equipped.Code != "" && item.Level >= c.GetItem(equipped.Code).Level {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -4,6 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
+)
+
+const (
+	TaskTypeMonsters = "monsters"
+	TaskTypeItems    = "items"
+
+	// monster tasks are abandoned after this many losses in a row
+	maxTaskLosses = 3
 )
 
 type AcceptTaskResponse struct {
@@ -16,6 +25,40 @@ type CompleteTaskResponse struct {
 	Error ErrorMessage     `json:"error"`
 }
 
+type TaskTradeResponse struct {
+	Data  TaskTradeData `json:"data"`
+	Error ErrorMessage  `json:"error"`
+}
+
+type TaskTradeData struct {
+	Cooldown  Cooldown   `json:"cooldown"`
+	Trade     SimpleItem `json:"trade"`
+	Character Character  `json:"character"`
+}
+
+type CancelTaskResponse struct {
+	Data  CancelTaskData `json:"data"`
+	Error ErrorMessage   `json:"error"`
+}
+
+type CancelTaskData struct {
+	Cooldown  Cooldown  `json:"cooldown"`
+	Character Character `json:"character"`
+}
+
+// TaskRunOptions are the stop conditions of the task runner. Zero values mean no limit.
+type TaskRunOptions struct {
+	MaxTasks int
+	Until    time.Time
+}
+
+func (o TaskRunOptions) done(completed int) bool {
+	if o.MaxTasks > 0 && completed >= o.MaxTasks {
+		return true
+	}
+	return !o.Until.IsZero() && time.Now().After(o.Until)
+}
+
 type AcceptTaskData struct {
 	Cooldown  Cooldown  `json:"cooldown"`
 	Character Character `json:"character"`
@@ -57,18 +100,18 @@ func (c *Svc) AcceptTask(characterName string) (*AcceptTaskResponse, error) {
 		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", acceptTaskResp.Error.Code, acceptTaskResp.Error.Message)
 	}
 
-	c.Characters[characterName] = &acceptTaskResp.Data.Character
+	c.setCharacter(characterName, &acceptTaskResp.Data.Character)
 	fmt.Printf("Task code: %s\n", acceptTaskResp.Data.Task.Code)
 	fmt.Printf("Task type: %s\n", acceptTaskResp.Data.Task.Type)
 	fmt.Printf("Task total: %d\n", acceptTaskResp.Data.Task.Total)
-	fmt.Printf("Task rewards: %s\n", acceptTaskResp.Data.Task.Rewards)
-	c.Characters[characterName].WaitForCooldown()
+	fmt.Printf("Task rewards: %v\n", acceptTaskResp.Data.Task.Rewards)
+	c.GetCharacterByName(characterName).WaitForCooldown()
 
 	return &acceptTaskResp, nil
 }
 
 func (c *Svc) CompleteTask(characterName string) (*CompleteTaskResponse, error) {
-	fmt.Printf("Accepting task\n")
+	fmt.Printf("Completing task\n")
 	path := fmt.Sprintf("/my/%s/action/task/complete", characterName)
 	respBytes, err := c.Client.Do(http.MethodPost, path, nil, nil)
 	if err != nil {
@@ -84,9 +127,262 @@ func (c *Svc) CompleteTask(characterName string) (*CompleteTaskResponse, error)
 		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", completeTaskResponse.Error.Code, completeTaskResponse.Error.Message)
 	}
 
-	c.Characters[characterName] = &completeTaskResponse.Data.Character
+	c.setCharacter(characterName, &completeTaskResponse.Data.Character)
 	fmt.Printf("Task rewards: %v\n", completeTaskResponse.Data.Rewards)
-	c.Characters[characterName].WaitForCooldown()
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	if err := c.updateLedger(characterName, func(ledger *TaskLedger) {
+		ledger.Completed++
+		ledger.addRewards(completeTaskResponse.Data.Rewards)
+	}); err != nil {
+		return nil, fmt.Errorf("recording task rewards: %w", err)
+	}
 
 	return &completeTaskResponse, nil
 }
+
+func (c *Svc) CancelTask(characterName string) (*CancelTaskResponse, error) {
+	fmt.Printf("Cancelling task\n")
+	path := fmt.Sprintf("/my/%s/action/task/cancel", characterName)
+	respBytes, err := c.Client.Do(http.MethodPost, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing cancel task request: %w", err)
+	}
+
+	cancelTaskResp := CancelTaskResponse{}
+	if err := json.Unmarshal(respBytes, &cancelTaskResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+
+	if cancelTaskResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", cancelTaskResp.Error.Code, cancelTaskResp.Error.Message)
+	}
+
+	c.setCharacter(characterName, &cancelTaskResp.Data.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	if err := c.updateLedger(characterName, func(ledger *TaskLedger) {
+		ledger.Cancelled++
+		ledger.CoinsSpent += taskCancelCost
+	}); err != nil {
+		return nil, fmt.Errorf("recording cancellation: %w", err)
+	}
+
+	return &cancelTaskResp, nil
+}
+
+// RunMonsterTasks runs monster tasks until a stop condition is met.
+func (c *Svc) RunMonsterTasks(characterName string, opts TaskRunOptions) error {
+	return c.RunTasks(characterName, TaskTypeMonsters, opts)
+}
+
+// RunItemTasks runs item tasks until a stop condition is met.
+func (c *Svc) RunItemTasks(characterName string, opts TaskRunOptions) error {
+	return c.RunTasks(characterName, TaskTypeItems, opts)
+}
+
+// RunTasks accepts tasks of taskType from the matching tasks master, works them to
+// completion and hands them in, until a stop condition is met. Tasks ruled out by the
+// character's TaskPolicy are cancelled, and the runner stops when there is no coin to
+// pay for that. Monster tasks are fought; item tasks are gathered, crafted or
+// withdrawn and traded in batches.
+func (c *Svc) RunTasks(characterName, taskType string, opts TaskRunOptions) error {
+	done, err := c.startLoop(characterName)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	completed := 0
+	for !opts.done(completed) {
+		character := c.GetCharacterByName(characterName)
+		if character.Task == "" {
+			if err := c.moveToContent(characterName, taskType); err != nil {
+				return fmt.Errorf("moving to tasks master: %w", err)
+			}
+			if _, err := c.AcceptTask(characterName); err != nil {
+				return fmt.Errorf("accepting task: %w", err)
+			}
+			character = c.GetCharacterByName(characterName)
+		}
+		if character.TaskType != taskType {
+			return fmt.Errorf("%s has a %s task, not a %s task", characterName, character.TaskType, taskType)
+		}
+		fmt.Printf("%s task: %s %d/%d\n", characterName, character.Task, character.TaskProgress, character.TaskTotal)
+
+		reason, err := c.shouldCancelTask(characterName)
+		if err != nil {
+			return fmt.Errorf("checking task policy: %w", err)
+		}
+		if reason != "" && character.TaskCoins() < taskCancelCost {
+			return fmt.Errorf("%s: %s and no task coins to cancel it", characterName, reason)
+		}
+		if reason != "" {
+			fmt.Printf("%s: %s, cancelling task\n", characterName, reason)
+			if err := c.moveToContent(characterName, taskType); err != nil {
+				return fmt.Errorf("moving to tasks master: %w", err)
+			}
+			if _, err := c.CancelTask(characterName); err != nil {
+				return fmt.Errorf("cancelling task: %w", err)
+			}
+			continue
+		}
+
+		switch taskType {
+		case TaskTypeMonsters:
+			if err := c.fightForTask(characterName); err != nil {
+				return fmt.Errorf("fighting for task %s: %w", character.Task, err)
+			}
+		case TaskTypeItems:
+			if err := c.tradeForTask(characterName); err != nil {
+				return fmt.Errorf("trading items for task %s: %w", character.Task, err)
+			}
+		default:
+			return fmt.Errorf("unknown task type %s", taskType)
+		}
+
+		if err := c.moveToContent(characterName, taskType); err != nil {
+			return fmt.Errorf("moving to tasks master: %w", err)
+		}
+		if _, err := c.CompleteTask(characterName); err != nil {
+			return fmt.Errorf("completing task: %w", err)
+		}
+		completed++
+
+		if err := c.exchangeSurplusCoins(characterName); err != nil {
+			return fmt.Errorf("exchanging task coins: %w", err)
+		}
+	}
+
+	fmt.Printf("%s completed %d tasks\n", characterName, completed)
+	return nil
+}
+
+// tradeForTask gathers, crafts or withdraws the task item in batches that fit the
+// inventory and trades each batch to the items tasks master.
+func (c *Svc) tradeForTask(characterName string) error {
+	character := c.GetCharacterByName(characterName)
+	code := character.Task
+	for character.TaskProgress < character.TaskTotal {
+		batch := character.TaskTotal - character.TaskProgress
+		if c.GetItem(code).Craft != nil {
+			if craftBatch := c.craftBatchSize(characterName, code); batch > craftBatch {
+				batch = craftBatch
+			}
+		}
+
+		_, inInventory := character.FindItemInInventory(code)
+		if inInventory < batch {
+			// keep what is held so obtainItem doesn't withdraw it again
+			if err := c.depositAllExcept(characterName, code); err != nil {
+				return fmt.Errorf("making room for %s: %w", code, err)
+			}
+			character = c.GetCharacterByName(characterName)
+			_, held := character.FindItemInInventory(code)
+			if space := character.FreeItemSpace() - c.inventoryPolicy(characterName).ItemBuffer; batch > held+space {
+				batch = held + space
+			}
+			if batch <= 0 {
+				return fmt.Errorf("no inventory space for %s", code)
+			}
+			if err := c.obtainItem(characterName, code, batch); err != nil {
+				return fmt.Errorf("obtaining %d %s: %w", batch, code, err)
+			}
+			character = c.GetCharacterByName(characterName)
+		}
+
+		_, inInventory = character.FindItemInInventory(code)
+		if inInventory > batch {
+			inInventory = batch
+		}
+		if inInventory <= 0 {
+			return fmt.Errorf("no %s in inventory to trade", code)
+		}
+		if err := c.moveToContent(characterName, TaskTypeItems); err != nil {
+			return fmt.Errorf("moving to tasks master: %w", err)
+		}
+		if _, err := c.TradeTaskItem(characterName, code, inInventory); err != nil {
+			return fmt.Errorf("trading %d %s: %w", inInventory, code, err)
+		}
+
+		character = c.GetCharacterByName(characterName)
+		fmt.Printf("%s task progress: %d/%d\n", characterName, character.TaskProgress, character.TaskTotal)
+	}
+	return nil
+}
+
+// TradeTaskItem hands quantity of code in to the items tasks master.
+func (c *Svc) TradeTaskItem(characterName, code string, quantity int) (*TaskTradeResponse, error) {
+	fmt.Printf("%s trading %d %s for task\n", characterName, quantity, code)
+	path := fmt.Sprintf("/my/%s/action/task/trade", characterName)
+	body := SimpleItem{
+		Code:     code,
+		Quantity: quantity,
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling body: %w", err)
+	}
+	respBytes, err := c.Client.Do(http.MethodPost, path, nil, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("executing task trade request: %w", err)
+	}
+
+	tradeResp := TaskTradeResponse{}
+	if err := json.Unmarshal(respBytes, &tradeResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+
+	if tradeResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", tradeResp.Error.Code, tradeResp.Error.Message)
+	}
+
+	c.setCharacter(characterName, &tradeResp.Data.Character)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	return &tradeResp, nil
+}
+
+// fightForTask fights the character's task monster until the task progress is complete.
+func (c *Svc) fightForTask(characterName string) error {
+	if err := c.EquipPendingUpgrades(characterName); err != nil {
+		return fmt.Errorf("equipping pending upgrades: %w", err)
+	}
+
+	monsterCode := c.GetCharacterByName(characterName).Task
+	return c.withLoadout(characterName, LoadoutCombat, func() error {
+		if err := c.moveToContent(characterName, monsterCode); err != nil {
+			return fmt.Errorf("moving to %s: %w", monsterCode, err)
+		}
+		character := c.GetCharacterByName(characterName)
+		coords := Coordinates{character.X, character.Y}
+
+		losses := 0
+		for character.TaskProgress < character.TaskTotal {
+			if err := c.prepareForFight(characterName, coords); err != nil {
+				return fmt.Errorf("preparing for fight: %w", err)
+			}
+
+			fightResp, err := c.Fight(characterName)
+			if err != nil {
+				return fmt.Errorf("executing fight request: %w", err)
+			}
+			if fightResp.Data.Fight.Result == "loss" {
+				// losses don't advance the task, so don't keep trying forever
+				if losses++; losses >= maxTaskLosses {
+					return fmt.Errorf("lost to %s %d times in a row", monsterCode, losses)
+				}
+				fmt.Println("Character lost, moving back to monster spawn")
+				if _, err := c.MoveCharacter(characterName, coords.X, coords.Y); err != nil {
+					return fmt.Errorf("moving back to monster: %w", err)
+				}
+			} else {
+				losses = 0
+			}
+
+			character = c.GetCharacterByName(characterName)
+			fmt.Printf("%s task progress: %d/%d\n", characterName, character.TaskProgress, character.TaskTotal)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	TaskCoinCode = "tasks_coin"
+
+	taskCancelCost   = 1
+	taskExchangeCost = 6
+
+	taskLedgerFile = "task_ledger.json"
+)
+
+type TaskExchangeResponse struct {
+	Data  TaskExchangeData `json:"data"`
+	Error ErrorMessage     `json:"error"`
+}
+
+type TaskExchangeData struct {
+	Cooldown  Cooldown    `json:"cooldown"`
+	Rewards   TaskRewards `json:"rewards"`
+	Character Character   `json:"character"`
+}
+
+// TaskPolicy decides when a task is cancelled rather than pushed through and what
+// happens to the coins it earns.
+type TaskPolicy struct {
+	// PushThroughUnwinnable keeps monster tasks the fight estimator predicts a loss for.
+	// The task is still given up after a few losses in a row.
+	PushThroughUnwinnable bool
+	// MaxSkillGap is how many levels an item task may require above the character's
+	// current skill before it is cancelled.
+	MaxSkillGap int
+	// AutoExchange trades coins for rewards after each completed task.
+	AutoExchange bool
+	// CoinReserve is how many coins are held back for future cancellations.
+	CoinReserve int
+}
+
+// TaskLedger accounts for everything a character earned and spent on tasks.
+type TaskLedger struct {
+	Completed   int            `json:"completed"`
+	Cancelled   int            `json:"cancelled"`
+	Exchanges   int            `json:"exchanges"`
+	CoinsEarned int            `json:"coins_earned"`
+	CoinsSpent  int            `json:"coins_spent"`
+	Gold        int            `json:"gold"`
+	Items       map[string]int `json:"items"`
+}
+
+type TaskEconomy struct {
+	mu       sync.Mutex
+	Policies map[string]TaskPolicy
+	Ledgers  map[string]TaskLedger
+}
+
+func NewTaskEconomy() TaskEconomy {
+	return TaskEconomy{
+		mu:       sync.Mutex{},
+		Policies: make(map[string]TaskPolicy),
+		Ledgers:  make(map[string]TaskLedger),
+	}
+}
+
+func (c *Svc) SetTaskPolicy(characterName string, policy TaskPolicy) {
+	c.TaskEconomy.mu.Lock()
+	defer c.TaskEconomy.mu.Unlock()
+	c.TaskEconomy.Policies[characterName] = policy
+}
+
+func (c *Svc) taskPolicy(characterName string) TaskPolicy {
+	c.TaskEconomy.mu.Lock()
+	defer c.TaskEconomy.mu.Unlock()
+	return c.TaskEconomy.Policies[characterName]
+}
+
+// GetTaskLedger returns what the character has earned and spent on tasks so far.
+func (c *Svc) GetTaskLedger(characterName string) TaskLedger {
+	c.TaskEconomy.mu.Lock()
+	defer c.TaskEconomy.mu.Unlock()
+
+	ledger := c.TaskEconomy.Ledgers[characterName]
+	items := make(map[string]int, len(ledger.Items))
+	for code, quantity := range ledger.Items {
+		items[code] = quantity
+	}
+	ledger.Items = items
+	return ledger
+}
+
+// updateLedger applies fn to the character's ledger and persists every ledger.
+func (c *Svc) updateLedger(characterName string, fn func(ledger *TaskLedger)) error {
+	c.TaskEconomy.mu.Lock()
+	defer c.TaskEconomy.mu.Unlock()
+
+	ledger := c.TaskEconomy.Ledgers[characterName]
+	if ledger.Items == nil {
+		ledger.Items = make(map[string]int)
+	}
+	fn(&ledger)
+	c.TaskEconomy.Ledgers[characterName] = ledger
+
+	if err := writeJSONFile(filepath.Join(c.DataDir, taskLedgerFile), c.TaskEconomy.Ledgers); err != nil {
+		return fmt.Errorf("saving task ledger: %w", err)
+	}
+	return nil
+}
+
+func (l *TaskLedger) addRewards(rewards TaskRewards) {
+	l.Gold += rewards.Gold
+	for _, item := range rewards.Items {
+		l.Items[item.Code] += item.Quantity
+		if item.Code == TaskCoinCode {
+			l.CoinsEarned += item.Quantity
+		}
+	}
+}
+
+func (c *Svc) populateTaskLedgers() error {
+	if err := readJSONFile(filepath.Join(c.DataDir, taskLedgerFile), &c.TaskEconomy.Ledgers); err != nil {
+		return fmt.Errorf("reading task ledger: %w", err)
+	}
+	fmt.Println("Task ledger successfully populated")
+	return nil
+}
+
+// TaskCoins returns how many task coins the character is carrying.
+func (c Character) TaskCoins() int {
+	_, quantity := c.FindItemInInventory(TaskCoinCode)
+	return quantity
+}
+
+// shouldCancelTask applies the task policy to the character's current task and
+// returns the reason to cancel it, if any.
+func (c *Svc) shouldCancelTask(characterName string) (string, error) {
+	character := c.GetCharacterByName(characterName)
+	policy := c.taskPolicy(characterName)
+
+	switch character.TaskType {
+	case TaskTypeMonsters:
+		if policy.PushThroughUnwinnable {
+			return "", nil
+		}
+		estimate, err := c.EstimateFight(characterName, character.Task)
+		if err != nil {
+			return "", fmt.Errorf("estimating fight against %s: %w", character.Task, err)
+		}
+		if !estimate.Win {
+			return fmt.Sprintf("can't beat %s", character.Task), nil
+		}
+	case TaskTypeItems:
+		skill, level := c.requiredSkill(c.GetItem(character.Task))
+		if gap := level - character.SkillLevel(skill); skill != "" && gap > policy.MaxSkillGap {
+			return fmt.Sprintf("%s needs %s level %d", character.Task, skill, level), nil
+		}
+	}
+	return "", nil
+}
+
+// requiredSkill returns the skill and level needed to produce item, if any.
+func (c *Svc) requiredSkill(item CraftableItem) (Skill, int) {
+	if item.Craft != nil {
+		return item.Craft.Skill, item.Craft.Level
+	}
+
+	var skill Skill
+	level := 0
+	for _, resource := range c.GetResourceByCode(item.Code) {
+		if skill == "" || resource.Level < level {
+			skill, level = resource.Skill, resource.Level
+		}
+	}
+	return skill, level
+}
+
+// ExchangeTaskCoins trades task coins for a random reward at the tasks master.
+func (c *Svc) ExchangeTaskCoins(characterName string) (*TaskExchangeResponse, error) {
+	fmt.Printf("%s exchanging %d task coins\n", characterName, taskExchangeCost)
+	path := fmt.Sprintf("/my/%s/action/task/exchange", characterName)
+	respBytes, err := c.Client.Do(http.MethodPost, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing task exchange request: %w", err)
+	}
+
+	exchangeResp := TaskExchangeResponse{}
+	if err := json.Unmarshal(respBytes, &exchangeResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling resp payload: %w", err)
+	}
+
+	if exchangeResp.Error.Code != 0 {
+		return nil, fmt.Errorf("error response received: status code: %d, error message: %s", exchangeResp.Error.Code, exchangeResp.Error.Message)
+	}
+
+	c.setCharacter(characterName, &exchangeResp.Data.Character)
+	fmt.Printf("Exchange rewards: %v\n", exchangeResp.Data.Rewards)
+	c.GetCharacterByName(characterName).WaitForCooldown()
+
+	if err := c.updateLedger(characterName, func(ledger *TaskLedger) {
+		ledger.Exchanges++
+		ledger.CoinsSpent += taskExchangeCost
+		ledger.addRewards(exchangeResp.Data.Rewards)
+	}); err != nil {
+		return nil, fmt.Errorf("recording exchange: %w", err)
+	}
+
+	return &exchangeResp, nil
+}
+
+// exchangeSurplusCoins exchanges coins while the character holds more than the
+// policy's reserve. The character must be at a tasks master.
+func (c *Svc) exchangeSurplusCoins(characterName string) error {
+	policy := c.taskPolicy(characterName)
+	if !policy.AutoExchange {
+		return nil
+	}
+	for c.GetCharacterByName(characterName).TaskCoins() >= taskExchangeCost+policy.CoinReserve {
+		if _, err := c.ExchangeTaskCoins(characterName); err != nil {
+			return fmt.Errorf("exchanging task coins: %w", err)
+		}
+	}
+	return nil
+}
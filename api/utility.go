@@ -0,0 +1,213 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const maxUtilityQuantity = 100
+
+var utilitySlots = []Slot{SlotUtility1, SlotUtility2}
+
+// UtilityPolicy controls how a character's utility slots are kept stocked.
+type UtilityPolicy struct {
+	// MinCount is the quantity a restock fills each utility slot up to.
+	MinCount int
+	// RestockThreshold triggers a restock once a slot holds fewer than this.
+	RestockThreshold int
+	// Preferred consumables are used before any other, in order.
+	Preferred []string
+}
+
+type Utilities struct {
+	mu       sync.Mutex
+	Policies map[string]UtilityPolicy
+	used     map[string]map[string]int
+}
+
+func NewUtilities() Utilities {
+	return Utilities{
+		mu:       sync.Mutex{},
+		Policies: make(map[string]UtilityPolicy),
+		used:     make(map[string]map[string]int),
+	}
+}
+
+func (c *Svc) SetUtilityPolicy(characterName string, policy UtilityPolicy) {
+	c.Utilities.mu.Lock()
+	defer c.Utilities.mu.Unlock()
+	c.Utilities.Policies[characterName] = policy
+}
+
+// UtilityConsumption returns the consumables used in fights this session by code.
+func (c *Svc) UtilityConsumption(characterName string) map[string]int {
+	c.Utilities.mu.Lock()
+	defer c.Utilities.mu.Unlock()
+
+	out := make(map[string]int)
+	for code, quantity := range c.Utilities.used[characterName] {
+		out[code] = quantity
+	}
+	return out
+}
+
+// recordUtilityUse compares the utility slots before and after a fight.
+func (c *Svc) recordUtilityUse(characterName string, before, after Character) {
+	c.Utilities.mu.Lock()
+	defer c.Utilities.mu.Unlock()
+
+	for _, slot := range utilitySlots {
+		was, now := before.EquippedItem(slot), after.EquippedItem(slot)
+		if was.Code == "" {
+			continue
+		}
+		used := was.Quantity
+		if now.Code == was.Code {
+			used -= now.Quantity
+		}
+		if used <= 0 {
+			continue
+		}
+		if c.Utilities.used[characterName] == nil {
+			c.Utilities.used[characterName] = make(map[string]int)
+		}
+		c.Utilities.used[characterName][was.Code] += used
+	}
+}
+
+func (c *Svc) printUtilityReport(characterName string) {
+	consumption := c.UtilityConsumption(characterName)
+	if len(consumption) == 0 {
+		return
+	}
+	fmt.Printf("%s consumables used this session: %v\n", characterName, consumption)
+}
+
+// utilityScore ranks consumables by the strength of their restore and boost effects.
+func utilityScore(item CraftableItem) float64 {
+	score := 0.0
+	for _, effect := range item.Effects {
+		if effect.Name == "restore" || strings.HasPrefix(effect.Name, "boost_") {
+			score += float64(effect.Value)
+		}
+	}
+	return score
+}
+
+// utilityCandidates lists the consumables the character may use, best first.
+func (c *Svc) utilityCandidates(character *Character, policy UtilityPolicy) []string {
+	available := c.bankSnapshot()
+	for _, slot := range character.Inventory {
+		if slot.Code != "" {
+			available[slot.Code] += slot.Quantity
+		}
+	}
+
+	preferred := map[string]int{}
+	for i, code := range policy.Preferred {
+		preferred[code] = len(policy.Preferred) - i
+	}
+
+	candidates := []string{}
+	for code, quantity := range available {
+		item := c.GetItem(code)
+		if quantity <= 0 || item.Type != "utility" || item.Level > character.Level {
+			continue
+		}
+		candidates = append(candidates, code)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if preferred[a] != preferred[b] {
+			return preferred[a] > preferred[b]
+		}
+		if sa, sb := utilityScore(c.GetItem(a)), utilityScore(c.GetItem(b)); sa != sb {
+			return sa > sb
+		}
+		return a < b
+	})
+	return candidates
+}
+
+// StockUtilities tops up utility slots below the restock threshold from the
+// inventory and bank according to the character's policy.
+func (c *Svc) StockUtilities(characterName string) error {
+	c.Utilities.mu.Lock()
+	policy, found := c.Utilities.Policies[characterName]
+	c.Utilities.mu.Unlock()
+	if !found || policy.MinCount <= 0 {
+		return nil
+	}
+
+	minCount := policy.MinCount
+	if minCount > maxUtilityQuantity {
+		minCount = maxUtilityQuantity
+	}
+
+	for i, slot := range utilitySlots {
+		character := c.GetCharacterByName(characterName)
+		current := character.EquippedItem(slot)
+		if current.Code != "" && current.Quantity >= policy.RestockThreshold {
+			continue
+		}
+		// the same consumable can't be held in both slots
+		other := character.EquippedItem(utilitySlots[1-i]).Code
+
+		code := current.Code
+		needed := minCount - current.Quantity
+		if code == "" || !c.hasStock(characterName, code) {
+			code = ""
+			for _, candidate := range c.utilityCandidates(character, policy) {
+				if candidate != other {
+					code = candidate
+					break
+				}
+			}
+		}
+		if code == "" {
+			fmt.Printf("%s has no consumables available for %s\n", characterName, slot)
+			continue
+		}
+
+		if code != current.Code && current.Code != "" {
+			if err := c.Unequip(characterName, slot, current.Quantity); err != nil {
+				return fmt.Errorf("unequipping %s: %w", slot, err)
+			}
+			needed = minCount
+		}
+
+		_, inInventory := c.GetCharacterByName(characterName).FindItemInInventory(code)
+		if inInventory < needed {
+			if _, err := c.WithdrawFromBankIfFound(characterName, code, needed-inInventory); err != nil {
+				return fmt.Errorf("withdrawing %s: %w", code, err)
+			}
+			c.GetCharacterByName(characterName).WaitForCooldown()
+			_, inInventory = c.GetCharacterByName(characterName).FindItemInInventory(code)
+		}
+		if inInventory > needed {
+			inInventory = needed
+		}
+		if inInventory <= 0 {
+			continue
+		}
+
+		fmt.Printf("%s stocking %s with %d %s\n", characterName, slot, inInventory, code)
+		if err := c.Equip(characterName, c.GetItem(code), slot, inInventory); err != nil {
+			return fmt.Errorf("equipping %d %s in %s: %w", inInventory, code, slot, err)
+		}
+	}
+	return nil
+}
+
+// hasStock reports whether the character or the bank holds any of code.
+func (c *Svc) hasStock(characterName, code string) bool {
+	if found, _ := c.GetCharacterByName(characterName).FindItemInInventory(code); found {
+		return true
+	}
+	c.Bank.mu.Lock()
+	defer c.Bank.mu.Unlock()
+	item, found := c.GetBankItemsByCode(code)
+	return found && item.Quantity > 0
+}
@@ -3,15 +3,40 @@ package main
 import (
 	"artifacts/api"
 	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"time"
+)
+
+const (
+	priceRecordInterval = 5 * time.Minute
+	eventPollInterval   = time.Minute
 )
 
 func main() {
 	// process flags
 	//itemPtr := flag.String("item", "", "provide item code that you wish to craft")
 	////fightMonsterPtr := flag.String("monster", "", "provide the monster you wish to fight")
-	//flag.Parse()
+	pricesPtr := flag.String("prices", "", "print recorded grand exchange price stats for an item code and exit")
+	windowPtr := flag.Duration("window", 24*time.Hour, "how far back the price stats look")
+	watchPtr := flag.String("watch", "", "comma separated item codes to record grand exchange prices for")
+	flag.Parse()
+
+	if *pricesPtr != "" {
+		// the recorded history is local, no need for a server connection
+		stats, err := api.ReadPriceStats(api.DefaultDataDir, *pricesPtr, *windowPtr)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s over %s: %d samples\n", stats.Code, *windowPtr, stats.Samples)
+		fmt.Printf("ask: min %d max %d avg %.1f\n", stats.MinAsk, stats.MaxAsk, stats.AvgAsk)
+		fmt.Printf("bid: min %d max %d avg %.1f\n", stats.MinBid, stats.MaxBid, stats.AvgBid)
+		fmt.Printf("spread: avg %.1f\n", stats.AvgSpread)
+		return
+	}
 
 	// set up app dependencies
 	token, ok := os.LookupEnv("API_TOKEN")
@@ -20,9 +45,21 @@ func main() {
 	}
 
 	service, err := api.NewSvc(token)
+	if errors.Is(err, api.ErrMaintenance) {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	if err != nil {
 		panic(err)
 	}
+
+	stopEvents := service.StartEventPoller(eventPollInterval)
+	defer stopEvents()
+	if *watchPtr != "" {
+		service.WatchPrices(strings.Split(*watchPtr, ",")...)
+		stop := service.StartPriceRecorder(priceRecordInterval)
+		defer stop()
+	}
 	//if err := service.RecycleItems("Kristi"); err != nil {
 	//	panic(err)
 	//}
@@ -99,6 +136,9 @@ func main() {
 		if character.Name == "Kristi" {
 			go func(characterName string) {
 				defer wg2.Done()
+				if err := service.OptimizeGear(characterName, api.GearTarget{Monster: "cow"}); err != nil {
+					panic(err)
+				}
 				if err := service.FightForCrafting(characterName, "cowhide", nil); err != nil {
 					panic(err)
 				}
@@ -107,24 +147,12 @@ func main() {
 		}
 		go func(characterName string) {
 			defer wg2.Done()
-			for service.GetCharacterByName(characterName).WoodcuttingLevel < 10 {
-				if _, err := service.CraftItem(characterName, "ash_plank", 5); err != nil {
-					panic(err)
-				}
+			if err := service.OptimizeGear(characterName, api.GearTarget{Skill: api.SkillWoodcutting}); err != nil {
+				panic(err)
+			}
+			if err := service.LevelSkill(characterName, api.SkillWoodcutting, 10); err != nil {
+				panic(err)
 			}
-
-			//if service.GetCharacterByName(characterName).HelmetSlot == "" {
-			//	if err := service.Equip(characterName, *item); err != nil {
-			//		panic(err)
-			//	}
-			//} else {
-			//	if err := service.RecycleItems(characterName); err != nil {
-			//		panic(err)
-			//	}
-			//	if err := service.DepositAllItems(characterName); err != nil {
-			//		panic(err)
-			//	}
-			//}
 		}(character.Name)
 	}
 	wg2.Wait()